@@ -0,0 +1,263 @@
+package logs
+
+import (
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// OnFull is the policy of AsyncWriter when its buffer is full.
+type OnFull int
+
+// Policies when the buffer is full.
+const (
+	// Block waits until the buffer has room.
+	Block OnFull = iota
+	// DropNewest drops the record being written.
+	DropNewest
+	// DropOldest drops the oldest record in the buffer.
+	DropOldest
+)
+
+const (
+	defaultAsyncBufferSize    = 1024
+	defaultAsyncFlushInterval = time.Second
+)
+
+// AsyncConfig is the configuration of AsyncWriter.
+type AsyncConfig struct {
+	// BufferSize is the number of records buffered. The default is 1024.
+	BufferSize int
+	// OnFull is the policy when the buffer is full. The default is Block.
+	OnFull OnFull
+	// FlushInterval is the interval to flush the writer and report dropped records. The default is 1 second.
+	FlushInterval time.Duration
+}
+
+// OptionAsync returns an OptionFunc for configuring the logger to write asynchronously.
+// The writer configured by the other options is wrapped by AsyncWriter regardless of the order of options.
+func OptionAsync(config AsyncConfig) OptionFunc {
+	return func(opt *Option) {
+		opt.async = &config
+	}
+}
+
+// asyncRecord is a serialized record buffered by AsyncWriter.
+type asyncRecord struct {
+	level Level
+	p     []byte
+}
+
+// AsyncWriter is an io.Writer which hands records to a background goroutine writing them to another writer.
+//
+// The number of records dropped by OnFull is reported periodically by a "N logs dropped" record at warn level.
+// Sync waits until the buffered records are written, and Close drains them and closes the writer.
+// It is safe for concurrent use.
+type AsyncWriter struct {
+	out     io.Writer
+	config  AsyncConfig
+	ch      chan asyncRecord
+	flushCh chan chan struct{}
+	done    chan struct{}
+	dropped uint64
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewAsyncWriter returns a new AsyncWriter writing to w.
+func NewAsyncWriter(w io.Writer, config AsyncConfig) *AsyncWriter {
+	if config.BufferSize <= 0 {
+		config.BufferSize = defaultAsyncBufferSize
+	}
+
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = defaultAsyncFlushInterval
+	}
+
+	x := &AsyncWriter{
+		out:     w,
+		config:  config,
+		ch:      make(chan asyncRecord, config.BufferSize),
+		flushCh: make(chan chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	go x.run()
+
+	return x
+}
+
+// Write buffers a copy of p.
+func (x *AsyncWriter) Write(p []byte) (int, error) {
+	return x.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel buffers a copy of p with its level, which is passed to the writer implementing zerolog.LevelWriter.
+func (x *AsyncWriter) WriteLevel(level Level, p []byte) (int, error) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	if x.closed {
+		return 0, os.ErrClosed
+	}
+
+	record := asyncRecord{level: level, p: append([]byte(nil), p...)}
+
+	switch x.config.OnFull {
+	case DropNewest:
+		select {
+		case x.ch <- record:
+		default:
+			atomic.AddUint64(&x.dropped, 1)
+		}
+	case DropOldest:
+		for sent := false; !sent; {
+			select {
+			case x.ch <- record:
+				sent = true
+			default:
+				select {
+				case <-x.ch:
+					atomic.AddUint64(&x.dropped, 1)
+				default:
+				}
+			}
+		}
+	default:
+		x.ch <- record
+	}
+
+	return len(p), nil
+}
+
+// Dropped returns the number of records dropped since the last report.
+func (x *AsyncWriter) Dropped() uint64 {
+	return atomic.LoadUint64(&x.dropped)
+}
+
+// Sync waits until the buffered records are written, and flushes the writer.
+func (x *AsyncWriter) Sync() error {
+	x.mu.RLock()
+
+	if x.closed {
+		x.mu.RUnlock()
+
+		return nil
+	}
+
+	reply := make(chan struct{})
+	x.flushCh <- reply
+	x.mu.RUnlock()
+
+	<-reply
+
+	return syncWriter(x.out)
+}
+
+// Close writes the buffered records and closes the writer.
+func (x *AsyncWriter) Close() error {
+	x.mu.Lock()
+
+	if x.closed {
+		x.mu.Unlock()
+
+		return nil
+	}
+
+	x.closed = true
+	close(x.ch)
+	x.mu.Unlock()
+
+	<-x.done
+
+	if err := syncWriter(x.out); err != nil {
+		return err
+	}
+
+	return closeWriter(x.out)
+}
+
+func (x *AsyncWriter) scoped() bool {
+	return wantsScope(x.out)
+}
+
+func (x *AsyncWriter) reportsErrors() bool {
+	return wantsErrorCaller(x.out)
+}
+
+// run writes the buffered records until the writer is closed.
+func (x *AsyncWriter) run() {
+	defer close(x.done)
+
+	ticker := time.NewTicker(x.config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case record, ok := <-x.ch:
+			if !ok {
+				x.report()
+
+				return
+			}
+
+			x.write(record)
+		case <-ticker.C:
+			x.report()
+			syncWriter(x.out) // nolint:errcheck
+		case reply := <-x.flushCh:
+			x.drain()
+			x.report()
+			close(reply)
+		}
+	}
+}
+
+// drain writes the records buffered at the moment.
+func (x *AsyncWriter) drain() {
+	for i := len(x.ch); i > 0; i-- {
+		select {
+		case record, ok := <-x.ch:
+			if !ok {
+				return
+			}
+
+			x.write(record)
+		default:
+			return
+		}
+	}
+}
+
+// write writes record to the writer. Errors are ignored as there is nowhere to report them.
+func (x *AsyncWriter) write(record asyncRecord) {
+	if writer, ok := x.out.(zerolog.LevelWriter); ok {
+		writer.WriteLevel(record.level, record.p) // nolint:errcheck
+
+		return
+	}
+
+	x.out.Write(record.p) // nolint:errcheck
+}
+
+// report writes the number of dropped records if any.
+func (x *AsyncWriter) report() {
+	dropped := atomic.SwapUint64(&x.dropped, 0)
+	if dropped == 0 {
+		return
+	}
+
+	logger := zerolog.New(x.out).With().Timestamp().Logger()
+	ev := logger.WithLevel(WarnLevel)
+
+	if wantsScope(x.out) {
+		ev.Bool(scopeFieldName, true)
+	}
+
+	ev.Uint64("dropped", dropped).Msgf("%d logs dropped", dropped)
+}
@@ -0,0 +1,173 @@
+package logs_test
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	logs "github.com/rtkym/logs-go"
+	"github.com/stretchr/testify/assert"
+)
+
+// gateWriter blocks writes until the gate is opened.
+type gateWriter struct {
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	started chan struct{}
+	gate    chan struct{}
+	once    sync.Once
+}
+
+func newGateWriter() *gateWriter {
+	return &gateWriter{started: make(chan struct{}), gate: make(chan struct{})}
+}
+
+func (w *gateWriter) Write(p []byte) (int, error) {
+	w.once.Do(func() { close(w.started) })
+	<-w.gate
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.buf.Write(p)
+}
+
+func (w *gateWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.buf.String()
+}
+
+// logRecord returns a record of msg encoded by zerolog, which is CBOR in binary_log builds.
+func logRecord(msg string) []byte {
+	var buf bytes.Buffer
+
+	logger := zerolog.New(&buf)
+	logger.Log().Msg(msg)
+
+	return buf.Bytes()
+}
+
+func TestAsyncWriter(t *testing.T) {
+	t.Run("option", func(t *testing.T) {
+		buf := &CloseBuffer{}
+		logger := logs.NewWithOption(
+			logs.OptionAsync(logs.AsyncConfig{}),
+			func(opt *logs.Option) { opt.Writer = jsonWriter(buf) },
+		)
+
+		for i := 0; i < 100; i++ {
+			logger.Info("test msg")
+		}
+
+		assert.NoError(t, logger.Sync())
+		assert.Equal(t, 100, strings.Count(buf.String(), `"message":"test msg"`))
+
+		logger.Warn("last msg")
+		assert.NoError(t, logger.Close())
+		assert.Contains(t, buf.String(), `"message":"last msg"`)
+		assert.Equal(t, "close", buf.calls[len(buf.calls)-1])
+	})
+
+	t.Run("Block", func(t *testing.T) {
+		out := newGateWriter()
+		w := logs.NewAsyncWriter(out, logs.AsyncConfig{BufferSize: 1, OnFull: logs.Block})
+
+		go func() {
+			<-out.started
+			time.Sleep(10 * time.Millisecond)
+			close(out.gate)
+		}()
+
+		for _, s := range []string{"0\n", "1\n", "2\n", "3\n"} {
+			_, err := w.Write([]byte(s))
+			assert.NoError(t, err)
+		}
+
+		assert.NoError(t, w.Close())
+		assert.Equal(t, "0\n1\n2\n3\n", out.String())
+	})
+
+	for _, tt := range []struct {
+		name   string
+		onFull logs.OnFull
+		want   string
+	}{
+		{"DropNewest", logs.DropNewest, `{"message":"0"}` + "\n" + `{"message":"1"}` + "\n"},
+		{"DropOldest", logs.DropOldest, `{"message":"0"}` + "\n" + `{"message":"3"}` + "\n"},
+	} {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			out := newGateWriter()
+			w := logs.NewAsyncWriter(jsonWriter(out), logs.AsyncConfig{BufferSize: 1, OnFull: tt.onFull})
+
+			_, err := w.Write(logRecord("0"))
+			assert.NoError(t, err)
+			<-out.started
+
+			for _, msg := range []string{"1", "2", "3"} {
+				_, err := w.Write(logRecord(msg))
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, uint64(2), w.Dropped())
+
+			close(out.gate)
+			assert.NoError(t, w.Close())
+
+			assert.True(t, strings.HasPrefix(out.String(), tt.want), out.String())
+			assert.Contains(t, out.String(), `{"level":"warn","dropped":2,`)
+			assert.Contains(t, out.String(), `"message":"2 logs dropped"}`)
+
+			_, err = w.Write(logRecord("closed"))
+			assert.Error(t, err)
+		})
+	}
+
+	t.Run("FlushInterval", func(t *testing.T) {
+		out := newGateWriter()
+		w := logs.NewAsyncWriter(jsonWriter(out), logs.AsyncConfig{BufferSize: 1, OnFull: logs.DropNewest, FlushInterval: 10 * time.Millisecond})
+
+		defer w.Close()
+
+		_, err := w.Write(logRecord("0"))
+		assert.NoError(t, err)
+		<-out.started
+
+		_, err = w.Write(logRecord("1"))
+		assert.NoError(t, err)
+		_, err = w.Write(logRecord("2"))
+		assert.NoError(t, err)
+
+		close(out.gate)
+
+		assert.Eventually(t, func() bool {
+			return strings.Contains(out.String(), `"message":"1 logs dropped"`)
+		}, time.Second, 10*time.Millisecond)
+		assert.Equal(t, uint64(0), w.Dropped())
+	})
+
+	t.Run("scope", func(t *testing.T) {
+		out := newGateWriter()
+		w := logs.NewAsyncWriter(logs.ExpNewScopeWriter(out), logs.AsyncConfig{BufferSize: 1, OnFull: logs.DropNewest})
+
+		_, err := w.Write(logRecord("0"))
+		assert.NoError(t, err)
+		<-out.started
+
+		_, err = w.Write(logRecord("1"))
+		assert.NoError(t, err)
+		_, err = w.Write(logRecord("2"))
+		assert.NoError(t, err)
+
+		close(out.gate)
+		assert.NoError(t, w.Close())
+
+		assert.Contains(t, out.String(), `{"level":"warn","_scope":true,"dropped":1,`)
+	})
+}
@@ -0,0 +1,10 @@
+//go:build binary_log
+// +build binary_log
+
+package logs
+
+// binaryLog is set in binary_log builds, where zerolog writes CBOR instead of JSON.
+const binaryLog = true
+
+// scopeMarker is the bytes of the scope field in CBOR records: the text string key and true.
+var scopeMarker = append(append([]byte{0x60 | byte(len(scopeFieldName))}, scopeFieldName...), 0xf5) // nolint:gochecknoglobals
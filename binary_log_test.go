@@ -0,0 +1,84 @@
+//go:build binary_log
+// +build binary_log
+
+package logs_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"testing"
+
+	logs "github.com/rtkym/logs-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBinaryLog(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger := logs.NewWithOption(func(opt *logs.Option) { opt.Writer = buf })
+		logger.Set("service", "api")
+
+		err := fmt.Errorf("wrapped: %w", &FieldsError{err: errors.New("test error")})
+		logger.V("str", "v").V("int", 1).V("float", 1.5).V("map", map[string]int{"a": 1}).E(err).Error("test msg")
+
+		assert.NotEqual(t, byte('{'), buf.Bytes()[0])
+
+		res := decodeCBOR(t, buf)
+		assert.Equal(t, "error", res["level"])
+		assert.Equal(t, "api", res["service"])
+		assert.Equal(t, "v", res["str"])
+		assert.Equal(t, float64(1), res["int"])
+		assert.Equal(t, 1.5, res["float"])
+		assert.Equal(t, map[string]interface{}{"a": float64(1)}, res["map"])
+		assert.Equal(t, "test msg", res["message"])
+		assert.NotEmpty(t, res["time"])
+		assert.Equal(t, map[string]interface{}{
+			"message": "wrapped: query failed: test error",
+			"type":    "*fmt.wrapError",
+			"causes": []interface{}{
+				map[string]interface{}{
+					"message": "query failed: test error",
+					"type":    "*logs_test.FieldsError",
+					"fields":  map[string]interface{}{"rows": float64(0), "table": "users"},
+				},
+				map[string]interface{}{"message": "test error", "type": "*errors.errorString"},
+			},
+		}, res["error"])
+	})
+
+	t.Run("stack", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger := logs.NewWithOption(func(opt *logs.Option) { opt.Writer = buf })
+		logger.E(NewStackError()).Error("test msg")
+
+		stack := decodeCBOR(t, buf)["error"].(map[string]interface{})["stack"].([]interface{})
+		assert.Equal(t, "github.com/rtkym/logs-go_test.TestBinaryLog.func2", stack[0].(map[string]interface{})["func"])
+	})
+
+	t.Run("formats", func(t *testing.T) {
+		assert.Contains(t, logs.Formats(), "cbor")
+
+		for _, tt := range []struct {
+			format string
+			want   string
+		}{
+			{"json", `"message":"test msg"`},
+			{"logfmt", `message="test msg"`},
+			{"ecs", `"message":"test msg"`},
+			{"console", `test msg`},
+		} {
+			buf := &bytes.Buffer{}
+			logger := logs.NewWithOption(func(opt *logs.Option) { opt.Writer = logs.ExpNewFormatWriter(tt.format, buf) })
+			logger.E(errors.New("test error")).Info("test msg")
+
+			assert.Contains(t, buf.String(), tt.want, tt.format)
+			assert.Contains(t, buf.String(), "test error", tt.format)
+		}
+
+		buf := &bytes.Buffer{}
+		logger := logs.NewWithOption(func(opt *logs.Option) { opt.Writer = logs.ExpNewFormatWriter("cbor", buf) })
+		logger.Info("test msg")
+		assert.Equal(t, "test msg", decodeCBOR(t, buf)["message"])
+	})
+}
@@ -0,0 +1,124 @@
+package logs
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// CallerFuncFieldName is the field name used for the function of the caller.
+var CallerFuncFieldName = "func" // nolint:gochecknoglobals
+
+// callerConfig configures caller annotation of a Logger.
+type callerConfig struct {
+	enabled bool
+	fn      bool
+	skip    int
+}
+
+// OptionCaller returns an OptionFunc for annotating messages with the file and line of the caller.
+// skip is the number of extra stack frames to skip, for loggers wrapped by other functions.
+func OptionCaller(skip int) OptionFunc {
+	return func(opt *Option) {
+		opt.Caller = true
+		opt.CallerSkip = skip
+	}
+}
+
+// OptionCallerFunc returns an OptionFunc for annotating messages with the function of the caller
+// in addition to the file and line.
+func OptionCallerFunc() OptionFunc {
+	return func(opt *Option) {
+		opt.Caller = true
+		opt.CallerFunc = true
+	}
+}
+
+// optionCallerEnv returns an OptionFunc for configuring caller annotation by LOG_CALLER.
+// It accepts boolean values, and "func" to annotate the function as well.
+func optionCallerEnv(value string) OptionFunc {
+	return func(opt *Option) {
+		if value == "" {
+			return
+		}
+
+		if strings.EqualFold(value, "func") {
+			OptionCallerFunc()(opt)
+
+			return
+		}
+
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			opt.addError(fmt.Errorf("invalid LOG_CALLER: %w", err))
+
+			return
+		}
+
+		opt.Caller = enabled
+	}
+}
+
+// AddCallerSkip returns a child logger which skips n more stack frames to find the caller.
+// It is for functions wrapping the logger, so that the caller of the wrapper is annotated.
+func (x *Logger) AddCallerSkip(n int) *Logger {
+	child := x.clone()
+	child.caller.skip += n
+
+	return child
+}
+
+// callerDepth is the number of frames from runtime.Callers to the caller of a LogEntry level method,
+// through LogEntry.callers, the function adding fields and LogEntry.msg.
+const callerDepth = 5
+
+// callers fills pcs with the program counters from the caller of the level method.
+// It must be called by a function called by LogEntry.msg.
+func (x *LogEntry) callers(skip int, pcs []uintptr) int {
+	return runtime.Callers(callerDepth+skip+x.logger.caller.skip+x.skip, pcs)
+}
+
+// addCaller adds caller fields to ev. skip is the number of frames between LogEntry.msg and the caller
+// of the level method, in addition to the skips of the logger and the entry.
+// The caller of errors is added for writers reporting errors even if caller annotation is disabled.
+func (x *LogEntry) addCaller(ev *zerolog.Event, level zerolog.Level, skip int) {
+	config := x.logger.caller
+	reportError := x.reportsError(level)
+
+	if !config.enabled && !reportError {
+		return
+	}
+
+	var pcs [1]uintptr
+	if x.pcs != nil {
+		pcs[0] = x.pcs[0]
+	} else if x.callers(skip, pcs[:]) == 0 {
+		return
+	}
+
+	frame, _ := runtime.CallersFrames(pcs[:]).Next()
+	ev.Str(zerolog.CallerFieldName, zerolog.CallerMarshalFunc(frame.PC, frame.File, frame.Line))
+
+	if config.fn || reportError {
+		ev.Str(CallerFuncFieldName, frame.Function)
+	}
+}
+
+// reportsError reports whether the entry at level is reported as an error by the writer. See errorReporter.
+// Only the error of zerolog.ErrorFieldName is reported, which the writers look up in the records.
+func (x *LogEntry) reportsError(level zerolog.Level) bool {
+	if !x.logger.core.reportErrors || level < zerolog.ErrorLevel || level > zerolog.PanicLevel {
+		return false
+	}
+
+	for i := range x.values {
+		if f := &x.values[i]; f.kind == kindError && f.key == zerolog.ErrorFieldName && f.value != nil {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,486 @@
+package logs
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// ErrInvalidCBOR is returned when a CBOR stream cannot be decoded.
+var ErrInvalidCBOR = errors.New("invalid CBOR")
+
+// CBOR major types, additional information and tags used by zerolog.
+const (
+	cborUnsignedInt = iota
+	cborNegativeInt
+	cborByteString
+	cborTextString
+	cborArray
+	cborMap
+	cborTag
+	cborSimple
+
+	cborIndefinite = 31
+	cborBreak      = 0xff
+
+	cborTagEpochTime    = 1
+	cborTagNetworkAddr  = 260
+	cborTagEmbeddedJSON = 262
+	cborTagHexString    = 263
+
+	// maxCBORStringSize is the maximum size of a string, which protects the decoder from corrupted streams.
+	maxCBORStringSize = 1 << 30
+)
+
+// CBORDecoder reads the CBOR records written by binary_log builds and converts them into JSON lines.
+type CBORDecoder struct {
+	r *bufio.Reader
+}
+
+// NewCBORDecoder returns a new CBORDecoder reading from r.
+func NewCBORDecoder(r io.Reader) *CBORDecoder {
+	return &CBORDecoder{r: bufio.NewReader(r)}
+}
+
+// Decode reads the next record and returns it as a JSON line. It returns io.EOF at the end of the stream.
+func (x *CBORDecoder) Decode() ([]byte, error) {
+	if _, err := x.r.Peek(1); err != nil {
+		return nil, err // nolint:wrapcheck
+	}
+
+	buf := &bytes.Buffer{}
+	if err := x.decode(buf); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+
+		return nil, err
+	}
+
+	buf.WriteByte('\n')
+
+	return buf.Bytes(), nil
+}
+
+// DecodeCBOR converts the CBOR stream of src into JSON lines written to dst.
+func DecodeCBOR(dst io.Writer, src io.Reader) error {
+	dec := NewCBORDecoder(src)
+
+	for {
+		line, err := dec.Decode()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if _, err := dst.Write(line); err != nil {
+			return err // nolint:wrapcheck
+		}
+	}
+}
+
+// cborJSONWriter converts the CBOR records of binary_log builds into JSON lines.
+type cborJSONWriter struct {
+	out io.Writer
+}
+
+// Write writes p as a JSON line. Records which cannot be decoded are written as is.
+func (x *cborJSONWriter) Write(p []byte) (int, error) {
+	line, err := cborToJSON(p)
+	if err != nil {
+		return x.out.Write(p) // nolint:wrapcheck
+	}
+
+	if _, err := x.out.Write(line); err != nil {
+		return 0, err // nolint:wrapcheck
+	}
+
+	return len(p), nil
+}
+
+// Sync flushes the output.
+func (x *cborJSONWriter) Sync() error {
+	return syncWriter(x.out)
+}
+
+// Close closes the output.
+func (x *cborJSONWriter) Close() error {
+	return closeWriter(x.out)
+}
+
+// cborToJSON converts a CBOR record into a JSON line.
+func cborToJSON(p []byte) ([]byte, error) {
+	return NewCBORDecoder(bytes.NewReader(p)).Decode()
+}
+
+// decode converts the next item into JSON.
+func (x *CBORDecoder) decode(buf *bytes.Buffer) error {
+	b, err := x.r.ReadByte()
+	if err != nil {
+		return err // nolint:wrapcheck
+	}
+
+	major, info := b>>5, b&0x1f
+
+	if major == cborSimple {
+		return x.decodeSimple(buf, info)
+	}
+
+	n, indefinite, err := x.readArgument(info)
+	if err != nil {
+		return err
+	}
+
+	switch major {
+	case cborUnsignedInt:
+		buf.WriteString(strconv.FormatUint(n, 10))
+	case cborNegativeInt:
+		if n == math.MaxUint64 {
+			buf.WriteString("-18446744073709551616")
+		} else {
+			buf.WriteString("-" + strconv.FormatUint(n+1, 10))
+		}
+	case cborByteString, cborTextString:
+		s, err := x.readString(major, n, indefinite)
+		if err != nil {
+			return err
+		}
+
+		appendJSON(buf, string(s))
+	case cborArray:
+		return x.decodeArray(buf, n, indefinite)
+	case cborMap:
+		return x.decodeMap(buf, n, indefinite)
+	case cborTag:
+		return x.decodeTag(buf, n)
+	}
+
+	return nil
+}
+
+// readArgument reads the argument of the additional information.
+func (x *CBORDecoder) readArgument(info byte) (uint64, bool, error) {
+	var size int
+
+	switch {
+	case info < 24: // nolint:gomnd
+		return uint64(info), false, nil
+	case info == 24: // nolint:gomnd
+		size = 1
+	case info == 25: // nolint:gomnd
+		size = 2
+	case info == 26: // nolint:gomnd
+		size = 4
+	case info == 27: // nolint:gomnd
+		size = 8
+	case info == cborIndefinite:
+		return 0, true, nil
+	default:
+		return 0, false, fmt.Errorf("%w: additional information %d", ErrInvalidCBOR, info)
+	}
+
+	b := make([]byte, 8) // nolint:gomnd
+	if _, err := io.ReadFull(x.r, b[8-size:]); err != nil {
+		return 0, false, err // nolint:wrapcheck
+	}
+
+	return binary.BigEndian.Uint64(b), false, nil
+}
+
+// readString reads a byte or text string of n bytes, or the chunks of an indefinite string.
+func (x *CBORDecoder) readString(major byte, n uint64, indefinite bool) ([]byte, error) {
+	if !indefinite {
+		if n > maxCBORStringSize {
+			return nil, fmt.Errorf("%w: string of %d bytes", ErrInvalidCBOR, n)
+		}
+
+		s := make([]byte, n)
+		_, err := io.ReadFull(x.r, s)
+
+		return s, err // nolint:wrapcheck
+	}
+
+	var s []byte
+
+	for {
+		b, err := x.r.ReadByte()
+		if err != nil {
+			return nil, err // nolint:wrapcheck
+		}
+
+		if b == cborBreak {
+			return s, nil
+		}
+
+		if b>>5 != major {
+			return nil, fmt.Errorf("%w: chunk of major type %d", ErrInvalidCBOR, b>>5)
+		}
+
+		size, _, err := x.readArgument(b & 0x1f)
+		if err != nil {
+			return nil, err
+		}
+
+		chunk, err := x.readString(major, size, false)
+		if err != nil {
+			return nil, err
+		}
+
+		s = append(s, chunk...)
+	}
+}
+
+// isBreak consumes the break code if it is next.
+func (x *CBORDecoder) isBreak() (bool, error) {
+	b, err := x.r.Peek(1)
+	if err != nil {
+		return false, err // nolint:wrapcheck
+	}
+
+	if b[0] != cborBreak {
+		return false, nil
+	}
+
+	_, err = x.r.ReadByte()
+
+	return true, err // nolint:wrapcheck
+}
+
+// decodeArray converts an array of n items, or an indefinite array, into JSON.
+func (x *CBORDecoder) decodeArray(buf *bytes.Buffer, n uint64, indefinite bool) error {
+	buf.WriteByte('[')
+
+	for i := uint64(0); indefinite || i < n; i++ {
+		if indefinite {
+			if end, err := x.isBreak(); err != nil || end {
+				buf.WriteByte(']')
+
+				return err
+			}
+		}
+
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		if err := x.decode(buf); err != nil {
+			return err
+		}
+	}
+
+	buf.WriteByte(']')
+
+	return nil
+}
+
+// decodeMap converts a map of n pairs, or an indefinite map, into JSON. Keys which are not strings are quoted.
+func (x *CBORDecoder) decodeMap(buf *bytes.Buffer, n uint64, indefinite bool) error {
+	buf.WriteByte('{')
+
+	key := &bytes.Buffer{}
+
+	for i := uint64(0); indefinite || i < n; i++ {
+		if indefinite {
+			if end, err := x.isBreak(); err != nil || end {
+				buf.WriteByte('}')
+
+				return err
+			}
+		}
+
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		key.Reset()
+
+		if err := x.decode(key); err != nil {
+			return err
+		}
+
+		if bytes.HasPrefix(key.Bytes(), []byte{'"'}) {
+			buf.Write(key.Bytes())
+		} else {
+			appendJSON(buf, key.String())
+		}
+
+		buf.WriteByte(':')
+
+		if err := x.decode(buf); err != nil {
+			return err
+		}
+	}
+
+	buf.WriteByte('}')
+
+	return nil
+}
+
+// decodeTag converts the item of tag into JSON.
+// Times are formatted in zerolog.TimeFieldFormat, and embedded JSON is written as is.
+func (x *CBORDecoder) decodeTag(buf *bytes.Buffer, tag uint64) error {
+	switch tag {
+	case cborTagEpochTime:
+		item := &bytes.Buffer{}
+		if err := x.decode(item); err != nil {
+			return err
+		}
+
+		appendCBORTime(buf, item.String())
+
+		return nil
+	case cborTagEmbeddedJSON, cborTagNetworkAddr, cborTagHexString:
+		b, err := x.r.ReadByte()
+		if err != nil {
+			return err // nolint:wrapcheck
+		}
+
+		n, indefinite, err := x.readArgument(b & 0x1f)
+		if err != nil {
+			return err
+		}
+
+		s, err := x.readString(b>>5, n, indefinite)
+		if err != nil {
+			return err
+		}
+
+		switch tag {
+		case cborTagEmbeddedJSON:
+			buf.Write(s)
+		case cborTagNetworkAddr:
+			appendJSON(buf, cborNetworkAddr(s))
+		default:
+			appendJSON(buf, hex.EncodeToString(s))
+		}
+
+		return nil
+	}
+
+	return x.decode(buf)
+}
+
+// decodeSimple converts a simple value or a float into JSON.
+// NaN and infinities are written as strings as zerolog does.
+func (x *CBORDecoder) decodeSimple(buf *bytes.Buffer, info byte) error {
+	var f float64
+
+	switch info {
+	case 20: // nolint:gomnd
+		buf.WriteString("false")
+
+		return nil
+	case 21: // nolint:gomnd
+		buf.WriteString("true")
+
+		return nil
+	case 22, 23: // nolint:gomnd
+		buf.WriteString("null")
+
+		return nil
+	case 25, 26, 27: // nolint:gomnd
+		n, _, err := x.readArgument(info)
+		if err != nil {
+			return err
+		}
+
+		switch info {
+		case 25: // nolint:gomnd
+			f = float64(halfToFloat32(uint16(n)))
+		case 26: // nolint:gomnd
+			f = float64(math.Float32frombits(uint32(n)))
+		default:
+			f = math.Float64frombits(n)
+		}
+	default:
+		return fmt.Errorf("%w: simple value %d", ErrInvalidCBOR, info)
+	}
+
+	switch {
+	case math.IsNaN(f):
+		buf.WriteString(`"NaN"`)
+	case math.IsInf(f, 1):
+		buf.WriteString(`"+Inf"`)
+	case math.IsInf(f, -1):
+		buf.WriteString(`"-Inf"`)
+	default:
+		buf.WriteString(strconv.FormatFloat(f, 'f', -1, 64))
+	}
+
+	return nil
+}
+
+// halfToFloat32 converts a half-precision float.
+func halfToFloat32(h uint16) float32 {
+	sign := uint32(h>>15) << 31
+	exp := uint32(h>>10) & 0x1f
+	frac := uint32(h) & 0x3ff
+
+	switch exp {
+	case 0:
+		f := float32(frac) / (1 << 24) // nolint:gomnd
+		if sign != 0 {
+			f = -f
+		}
+
+		return f
+	case 0x1f:
+		return math.Float32frombits(sign | 0xff<<23 | frac<<13)
+	}
+
+	return math.Float32frombits(sign | (exp+127-15)<<23 | frac<<13)
+}
+
+// appendCBORTime formats the epoch seconds of the JSON number s in zerolog.TimeFieldFormat.
+func appendCBORTime(buf *bytes.Buffer, s string) {
+	seconds, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		buf.WriteString(s)
+
+		return
+	}
+
+	var t time.Time
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		t = time.Unix(i, 0)
+	} else {
+		sec, frac := math.Modf(seconds)
+		t = time.Unix(int64(sec), int64(math.Round(frac*1e9))) // nolint:gomnd
+	}
+
+	switch zerolog.TimeFieldFormat {
+	case zerolog.TimeFormatUnix:
+		buf.WriteString(strconv.FormatInt(t.Unix(), 10))
+	case zerolog.TimeFormatUnixMs:
+		buf.WriteString(strconv.FormatInt(t.UnixMilli(), 10))
+	case zerolog.TimeFormatUnixMicro:
+		buf.WriteString(strconv.FormatInt(t.UnixMicro(), 10))
+	case zerolog.TimeFormatUnixNano:
+		buf.WriteString(strconv.FormatInt(t.UnixNano(), 10))
+	default:
+		appendJSON(buf, t.Format(zerolog.TimeFieldFormat))
+	}
+}
+
+// cborNetworkAddr formats an IP or MAC address.
+func cborNetworkAddr(b []byte) string {
+	switch len(b) {
+	case net.IPv4len, net.IPv6len:
+		return net.IP(b).String()
+	}
+
+	return net.HardwareAddr(b).String()
+}
@@ -0,0 +1,67 @@
+package logs_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	logs "github.com/rtkym/logs-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCBORDecoder(t *testing.T) {
+	defer func(format string) { zerolog.TimeFieldFormat = format }(zerolog.TimeFieldFormat)
+
+	zerolog.TimeFieldFormat = time.RFC3339
+
+	stream := []byte{
+		// {_ "level": "info", "n": -2, "u": 500, "f": 1.5, "h": 0.5, "b": true, "z": null, "a": [_ 1, "x"], "m": {1: h'6869'}}
+		0xbf,
+		0x65, 'l', 'e', 'v', 'e', 'l', 0x64, 'i', 'n', 'f', 'o',
+		0x61, 'n', 0x21,
+		0x61, 'u', 0x19, 0x01, 0xf4,
+		0x61, 'f', 0xfb, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0,
+		0x61, 'h', 0xf9, 0x38, 0x00,
+		0x61, 'b', 0xf5,
+		0x61, 'z', 0xf6,
+		0x61, 'a', 0x9f, 0x01, 0x61, 'x', 0xff,
+		0x61, 'm', 0xa1, 0x01, 0x42, 'h', 'i',
+		0xff,
+		// {"time": 1(1660626347), "j": 262(h'7b7d'), "ip": 260(h'7f000001')}
+		0xa3,
+		0x64, 't', 'i', 'm', 'e', 0xc1, 0x1a, 0x62, 0xfb, 0x25, 0xab,
+		0x61, 'j', 0xd9, 0x01, 0x06, 0x42, '{', '}',
+		0x62, 'i', 'p', 0xd9, 0x01, 0x04, 0x44, 127, 0, 0, 1,
+	}
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, logs.DecodeCBOR(buf, bytes.NewReader(stream)))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assert.Len(t, lines, 2)
+	assert.Equal(t, `{"level":"info","n":-2,"u":500,"f":1.5,"h":0.5,"b":true,"z":null,"a":[1,"x"],"m":{"1":"hi"}}`, lines[0])
+	assert.Equal(t, `{"time":"`+time.Unix(1660626347, 0).Format(time.RFC3339)+`","j":{},"ip":"127.0.0.1"}`, lines[1])
+
+	dec := logs.NewCBORDecoder(bytes.NewReader(stream[:10]))
+	_, err := dec.Decode()
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+	_, err = logs.NewCBORDecoder(bytes.NewReader(nil)).Decode()
+	assert.ErrorIs(t, err, io.EOF)
+
+	_, err = logs.NewCBORDecoder(bytes.NewReader([]byte{0x1c})).Decode()
+	assert.ErrorIs(t, err, logs.ErrInvalidCBOR)
+}
+
+// decodeCBOR decodes the CBOR record written to buf.
+func decodeCBOR(t *testing.T, buf *bytes.Buffer) map[string]interface{} {
+	t.Helper()
+
+	line, err := logs.NewCBORDecoder(buf).Decode()
+	assert.NoError(t, err)
+
+	return decodeLine(t, bytes.NewBuffer(line))
+}
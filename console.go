@@ -0,0 +1,454 @@
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+var envNoColor = os.Getenv("NO_COLOR") // nolint:gochecknoglobals
+
+// ColorMode is the mode of colors of the console format.
+type ColorMode int
+
+const (
+	// ColorAuto colors the output if it is a terminal and NO_COLOR is not set.
+	ColorAuto ColorMode = iota
+	// ColorAlways always colors the output.
+	ColorAlways
+	// ColorNever never colors the output.
+	ColorNever
+)
+
+// ConsoleConfig is the configuration of the console format.
+type ConsoleConfig struct {
+	// Out is the destination of the console format. The default is the output of the logger, such as LOG_OUTPUT.
+	Out io.Writer
+	// TimeFormat is the layout of the time. The default is zerolog.TimeFieldFormat.
+	TimeFormat string
+	// Color is whether to color the output. The default is ColorAuto.
+	Color ColorMode
+	// FieldOrder is the fields output first in this order. The other fields follow in alphabetical order.
+	FieldOrder []string
+	// ExcludeFields is the fields not to output. It may also have "time", "level", "caller" and "message".
+	ExcludeFields []string
+	// MessageFirst outputs the message before the time and the level.
+	MessageFirst bool
+	// Pretty outputs objects and arrays in indented multiple lines.
+	Pretty bool
+}
+
+// newConsoleWriter returns a zerolog.ConsoleWriter writing to out, which writes the fields in the form of "{key:value}".
+func newConsoleWriter(out io.Writer) *zerolog.ConsoleWriter {
+	writer := &zerolog.ConsoleWriter{
+		Out:           out,
+		TimeFormat:    zerolog.TimeFieldFormat,
+		FieldsExclude: []string{zerolog.ErrorStackFieldName},
+	}
+
+	if binaryLog {
+		writer.FormatTimestamp = consoleCBORTimestamp(writer)
+	}
+
+	writer.FormatLevel = func(i interface{}) string {
+		return fmt.Sprintf("%-5s", i)
+	}
+	writer.FormatMessage = func(i interface{}) string {
+		return fmt.Sprintf("%s", i)
+	}
+	writer.FormatFieldName = func(i interface{}) string {
+		return fmt.Sprintf("{%s:", i)
+	}
+	writer.FormatFieldValue = func(i interface{}) string {
+		return consoleFormattedValue(i) + "}"
+	}
+	writer.FormatErrFieldName = func(i interface{}) string {
+		return consoleColorize(fmt.Sprintf("%s=", i), 36, writer.NoColor) // nolint:gomnd
+	}
+	writer.FormatErrFieldValue = func(i interface{}) string {
+		return consoleColorize(consoleFormattedValue(i), 31, writer.NoColor) // nolint:gomnd
+	}
+	writer.FormatExtra = func(evt map[string]interface{}, buf *bytes.Buffer) error {
+		writeConsoleStacks(buf, evt, nil)
+
+		return nil
+	}
+
+	return writer
+}
+
+// consoleWriter writes records in the console format configured by ConsoleConfig.
+// It decodes and writes the records by itself, since zerolog.ConsoleWriter only sorts the fields alphabetically.
+type consoleWriter struct {
+	out     io.Writer
+	cfg     ConsoleConfig
+	noColor bool
+	parts   []string
+	exclude []string
+}
+
+// newConsoleWriterWith returns a consoleWriter configured with cfg writing to out.
+func newConsoleWriterWith(out io.Writer, cfg ConsoleConfig) *consoleWriter {
+	x := &consoleWriter{out: out, cfg: cfg, noColor: !consoleColor(cfg.Color, out)}
+
+	if x.cfg.TimeFormat == "" {
+		x.cfg.TimeFormat = zerolog.TimeFieldFormat
+	}
+
+	if x.cfg.TimeFormat == "" {
+		x.cfg.TimeFormat = time.Kitchen
+	}
+
+	parts := []string{zerolog.TimestampFieldName, zerolog.LevelFieldName, zerolog.CallerFieldName, zerolog.MessageFieldName}
+	if cfg.MessageFirst {
+		parts = []string{zerolog.MessageFieldName, zerolog.TimestampFieldName, zerolog.LevelFieldName, zerolog.CallerFieldName}
+	}
+
+	for _, part := range parts {
+		if !containsString(cfg.ExcludeFields, part) {
+			x.parts = append(x.parts, part)
+		}
+	}
+
+	x.exclude = append([]string{zerolog.ErrorStackFieldName}, cfg.ExcludeFields...)
+
+	return x
+}
+
+// Write writes p in the console format.
+func (x *consoleWriter) Write(p []byte) (int, error) {
+	evt, err := decodeConsoleEvent(p)
+	if err != nil {
+		return 0, fmt.Errorf("cannot decode event: %w", err)
+	}
+
+	buf := &bytes.Buffer{}
+
+	for _, part := range x.parts {
+		if s := x.formatPart(part, evt[part]); s != "" {
+			if buf.Len() > 0 {
+				buf.WriteByte(' ')
+			}
+
+			buf.WriteString(s)
+		}
+	}
+
+	writeConsoleFields(buf, consoleFields(evt, x.cfg.FieldOrder, x.exclude), evt, x.cfg.Pretty, x.noColor)
+	writeConsoleStacks(buf, evt, x.cfg.ExcludeFields)
+	buf.WriteByte('\n')
+
+	if _, err := x.out.Write(buf.Bytes()); err != nil {
+		return 0, err // nolint:wrapcheck
+	}
+
+	return len(p), nil
+}
+
+// Sync flushes the output.
+func (x *consoleWriter) Sync() error {
+	return syncWriter(x.out)
+}
+
+// Close closes the output.
+func (x *consoleWriter) Close() error {
+	return closeWriter(x.out)
+}
+
+// formatPart formats the value of the time, the level, the caller or the message,
+// in the same way as zerolog.ConsoleWriter with the formatters of newConsoleWriter.
+func (x *consoleWriter) formatPart(part string, value interface{}) string {
+	if value == nil {
+		return ""
+	}
+
+	switch part {
+	case zerolog.TimestampFieldName:
+		return consoleColorize(consoleTime(value, x.cfg.TimeFormat), 90, x.noColor) // nolint:gomnd
+	case zerolog.LevelFieldName:
+		return fmt.Sprintf("%-5s", value)
+	case zerolog.CallerFieldName:
+		return consoleCaller(value, x.noColor)
+	}
+
+	return fmt.Sprintf("%s", value)
+}
+
+// decodeConsoleEvent decodes a JSON line, or a CBOR record in binary_log builds, into a map.
+func decodeConsoleEvent(p []byte) (map[string]interface{}, error) {
+	if binaryLog {
+		line, err := cborToJSON(p)
+		if err != nil {
+			return nil, err
+		}
+
+		p = line
+	}
+
+	var evt map[string]interface{}
+
+	dec := json.NewDecoder(bytes.NewReader(p))
+	dec.UseNumber()
+
+	if err := dec.Decode(&evt); err != nil {
+		return nil, err // nolint:wrapcheck
+	}
+
+	return evt, nil
+}
+
+// consoleTime formats the time in zerolog.TimeFieldFormat with layout.
+func consoleTime(value interface{}, layout string) string {
+	switch v := value.(type) {
+	case string:
+		t, err := time.Parse(zerolog.TimeFieldFormat, v)
+		if err != nil {
+			return v
+		}
+
+		return t.Local().Format(layout)
+	case json.Number:
+		i, err := v.Int64()
+		if err != nil {
+			return v.String()
+		}
+
+		switch zerolog.TimeFieldFormat {
+		case zerolog.TimeFormatUnixMs:
+			return time.UnixMilli(i).Format(layout)
+		case zerolog.TimeFormatUnixMicro:
+			return time.UnixMicro(i).Format(layout)
+		case zerolog.TimeFormatUnixNano:
+			return time.Unix(0, i).Format(layout)
+		}
+
+		return time.Unix(i, 0).Format(layout)
+	}
+
+	return fmt.Sprintf("%v", value)
+}
+
+// consoleCaller formats the caller relative to the working directory.
+func consoleCaller(value interface{}, noColor bool) string {
+	caller := fmt.Sprintf("%s", value)
+
+	if cwd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(cwd, caller); err == nil {
+			caller = rel
+		}
+	}
+
+	return consoleColorize(caller, 1, noColor) + consoleColorize(" >", 36, noColor) // nolint:gomnd
+}
+
+// consoleCBORTimestamp formats the times which zerolog.ConsoleWriter decodes from CBOR.
+// They are in RFC 3339 with the trailing zeros of the nanoseconds trimmed, which zerolog.TimeFieldFormat may not parse.
+func consoleCBORTimestamp(writer *zerolog.ConsoleWriter) zerolog.Formatter {
+	return func(i interface{}) string {
+		s := fmt.Sprintf("%v", i)
+		if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+			s = t.Local().Format(writer.TimeFormat)
+		}
+
+		return consoleColorize(s, 90, writer.NoColor) // nolint:gomnd
+	}
+}
+
+// consoleColor reports whether to color the output to out in mode.
+func consoleColor(mode ColorMode, out io.Writer) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+
+	if envNoColor != "" {
+		return false
+	}
+
+	f, ok := out.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := f.Stat()
+
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// consoleFields returns the fields of evt to write except exclude, the fields of order first,
+// then the error and the other fields in alphabetical order.
+func consoleFields(evt map[string]interface{}, order, exclude []string) []string {
+	fields := make([]string, 0, len(evt))
+
+	for field := range evt {
+		switch field {
+		case zerolog.LevelFieldName, zerolog.TimestampFieldName, zerolog.MessageFieldName, zerolog.CallerFieldName:
+			continue
+		}
+
+		if !containsString(exclude, field) {
+			fields = append(fields, field)
+		}
+	}
+
+	rank := func(field string) int {
+		for i, ordered := range order {
+			if field == ordered {
+				return i
+			}
+		}
+
+		if field == zerolog.ErrorFieldName {
+			return len(order)
+		}
+
+		return len(order) + 1
+	}
+
+	sort.Slice(fields, func(i, j int) bool {
+		if ri, rj := rank(fields[i]), rank(fields[j]); ri != rj {
+			return ri < rj
+		}
+
+		return fields[i] < fields[j]
+	})
+
+	return fields
+}
+
+// writeConsoleFields writes fields of evt in the form of "{key:value}", or "error=value" for the error.
+func writeConsoleFields(buf *bytes.Buffer, fields []string, evt map[string]interface{}, pretty, noColor bool) {
+	for _, field := range fields {
+		if buf.Len() > 0 {
+			buf.WriteByte(' ')
+		}
+
+		value := consoleValue(evt[field], pretty)
+
+		if field == zerolog.ErrorFieldName {
+			buf.WriteString(consoleColorize(field+"=", 36, noColor)) // nolint:gomnd
+			buf.WriteString(consoleColorize(value, 31, noColor))     // nolint:gomnd
+		} else {
+			buf.WriteString("{" + field + ":" + value + "}")
+		}
+	}
+}
+
+// consoleValue formats a value of the fields. Strings are quoted if they have spaces or special characters.
+func consoleValue(value interface{}, pretty bool) string {
+	switch v := value.(type) {
+	case string:
+		if consoleNeedsQuote(v) {
+			return strconv.Quote(v)
+		}
+
+		return v
+	case json.Number:
+		return string(v)
+	}
+
+	b, err := zerolog.InterfaceMarshalFunc(value)
+	if err != nil {
+		return fmt.Sprintf("[error: %v]", err)
+	}
+
+	b = withoutStack(b)
+
+	if indented := (&bytes.Buffer{}); pretty && json.Indent(indented, b, "", "  ") == nil {
+		return indented.String()
+	}
+
+	return string(b)
+}
+
+// consoleFormattedValue formats a value of the fields passed by zerolog.ConsoleWriter,
+// which has quoted the strings and marshaled the objects and the arrays to JSON.
+func consoleFormattedValue(value interface{}) string {
+	if b, ok := value.([]byte); ok {
+		return string(withoutStack(b))
+	}
+
+	return fmt.Sprintf("%s", value)
+}
+
+// consoleNeedsQuote reports whether s needs to be quoted, the same as zerolog.ConsoleWriter.
+func consoleNeedsQuote(s string) bool {
+	for i := range s {
+		if s[i] < 0x20 || s[i] > 0x7e || s[i] == ' ' || s[i] == '\\' || s[i] == '"' {
+			return true
+		}
+	}
+
+	return false
+}
+
+// consoleColorize returns s wrapped in the ANSI color c unless noColor is true.
+func consoleColorize(s string, c int, noColor bool) string {
+	if noColor {
+		return s
+	}
+
+	return fmt.Sprintf("\x1b[%dm%s\x1b[0m", c, s)
+}
+
+// withoutStack removes the stack trace from an error value marshaled to JSON.
+func withoutStack(b []byte) []byte {
+	var errValue map[string]interface{}
+	if err := json.Unmarshal(b, &errValue); err != nil || errValue[zerolog.ErrorStackFieldName] == nil {
+		return b
+	}
+
+	delete(errValue, zerolog.ErrorStackFieldName)
+
+	if stripped, err := json.Marshal(errValue); err == nil {
+		return stripped
+	}
+
+	return b
+}
+
+// writeConsoleStacks writes the stack trace of evt and those of the errors in its fields, except the fields of exclude.
+func writeConsoleStacks(buf *bytes.Buffer, evt map[string]interface{}, exclude []string) {
+	if !containsString(exclude, zerolog.ErrorStackFieldName) {
+		writeConsoleStack(buf, "stack", evt[zerolog.ErrorStackFieldName])
+	}
+
+	keys := make([]string, 0, len(evt))
+	for key := range evt {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if errValue, ok := evt[key].(map[string]interface{}); ok && !containsString(exclude, key) {
+			writeConsoleStack(buf, key+" stack", errValue[zerolog.ErrorStackFieldName])
+		}
+	}
+}
+
+// writeConsoleStack writes a stack trace decoded from Frame values in the format of Go's panic.
+func writeConsoleStack(buf *bytes.Buffer, title string, stack interface{}) {
+	frames, ok := stack.([]interface{})
+	if !ok || len(frames) == 0 {
+		return
+	}
+
+	buf.WriteString("\n" + title + ":")
+
+	for _, f := range frames {
+		if frame, ok := f.(map[string]interface{}); ok {
+			fmt.Fprintf(buf, "\n\t%v\n\t\t%v:%v", frame["func"], frame["file"], frame["line"])
+		}
+	}
+}
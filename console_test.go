@@ -0,0 +1,159 @@
+package logs_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	logs "github.com/rtkym/logs-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsoleConfig(t *testing.T) {
+	newLogger := func(cfg logs.ConsoleConfig) (*logs.Logger, *bytes.Buffer) {
+		buf := &bytes.Buffer{}
+		cfg.Out = buf
+
+		return logs.NewWithOption(logs.OptionConsoleWriterWith(cfg)), buf
+	}
+
+	t.Run("writer", func(t *testing.T) {
+		buf1, buf2 := &bytes.Buffer{}, &bytes.Buffer{}
+		opt := &logs.Option{}
+		logs.OptionConsoleWriterWith(logs.ConsoleConfig{Out: buf1, ExcludeFields: []string{"time"}})(opt)
+		opt.Writer = io.MultiWriter(opt.Writer, buf2)
+
+		logger := logs.NewWithOption(func(o *logs.Option) { o.Writer = opt.Writer })
+		logger.V("n", 1).Info("test msg")
+
+		assert.Equal(t, "info  test msg {n:1}\n", buf1.String())
+		assert.Contains(t, buf2.String(), "test msg")
+	})
+
+	t.Run("default", func(t *testing.T) {
+		logger, buf := newLogger(logs.ConsoleConfig{})
+		logger.Set("set1", "a b")
+		logger.V("n", 1).Info("test msg")
+
+		assert.Regexp(t, `^\S+ info  test msg \{n:1\} \{set1:"a b"\}\n$`, buf.String())
+		assert.NotContains(t, buf.String(), "\x1b[")
+	})
+
+	t.Run("time format", func(t *testing.T) {
+		logger, buf := newLogger(logs.ConsoleConfig{TimeFormat: time.Kitchen})
+		logger.Info("test msg")
+
+		assert.Regexp(t, `^\d{1,2}:\d{2}[AP]M info  test msg\n$`, buf.String())
+	})
+
+	t.Run("color", func(t *testing.T) {
+		logger, buf := newLogger(logs.ConsoleConfig{Color: logs.ColorAlways})
+		logger.E(assert.AnError).Error("test msg")
+
+		assert.Contains(t, buf.String(), "\x1b[36merror=\x1b[0m\x1b[31m{")
+
+		logger, buf = newLogger(logs.ConsoleConfig{Color: logs.ColorNever})
+		logger.E(assert.AnError).Error("test msg")
+
+		assert.NotContains(t, buf.String(), "\x1b[")
+	})
+
+	t.Run("color auto", func(t *testing.T) {
+		assert.False(t, logs.ExpConsoleColor(logs.ColorAuto, &bytes.Buffer{}))
+		assert.True(t, logs.ExpConsoleColor(logs.ColorAlways, &bytes.Buffer{}))
+		assert.False(t, logs.ExpConsoleColor(logs.ColorNever, os.Stdout))
+
+		f, err := os.Create(t.TempDir() + "/app.log")
+		assert.NoError(t, err)
+		defer f.Close()
+
+		assert.False(t, logs.ExpConsoleColor(logs.ColorAuto, f))
+
+		if tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0); err == nil {
+			defer tty.Close()
+
+			assert.True(t, logs.ExpConsoleColor(logs.ColorAuto, tty))
+
+			defer logs.ExpSetNoColor("1")()
+
+			assert.False(t, logs.ExpConsoleColor(logs.ColorAuto, tty))
+		}
+	})
+
+	t.Run("field order", func(t *testing.T) {
+		logger, buf := newLogger(logs.ConsoleConfig{FieldOrder: []string{"request_id", "user"}})
+		logger.Set("a", 1)
+		logger.V("user", "bob").V("b", 2).V("request_id", "r1").E(assert.AnError).Info("test msg")
+
+		assert.Contains(t, buf.String(), ` test msg {request_id:r1} {user:bob} error={"message":`)
+		assert.Contains(t, buf.String(), `} {a:1} {b:2}`+"\n")
+	})
+
+	t.Run("exclude fields", func(t *testing.T) {
+		logger, buf := newLogger(logs.ConsoleConfig{ExcludeFields: []string{"time", "secret"}})
+		logger.V("secret", "x").V("n", 1).Info("test msg")
+
+		assert.Equal(t, "info  test msg {n:1}\n", buf.String())
+
+		buf.Reset()
+		logger.V("secret", "x").Info("test msg")
+
+		assert.Equal(t, "info  test msg\n", buf.String())
+
+		logger, buf = newLogger(logs.ConsoleConfig{ExcludeFields: []string{"time", "error"}})
+		logger.E(NewStackError()).Error("test msg")
+
+		assert.Equal(t, "error test msg\n", buf.String())
+	})
+
+	t.Run("message first", func(t *testing.T) {
+		logger, buf := newLogger(logs.ConsoleConfig{MessageFirst: true, ExcludeFields: []string{"time"}})
+		logger.V("n", 1).Warn("test msg")
+
+		assert.Equal(t, "test msg warn  {n:1}\n", buf.String())
+	})
+
+	t.Run("pretty", func(t *testing.T) {
+		logger, buf := newLogger(logs.ConsoleConfig{Pretty: true, ExcludeFields: []string{"time"}})
+		logger.V("m", map[string]interface{}{"a": 1, "b": []int{1, 2}}).V("n", 1).Info("test msg")
+
+		assert.Equal(t, "info  test msg {m:{\n  \"a\": 1,\n  \"b\": [\n    1,\n    2\n  ]\n}} {n:1}\n", buf.String())
+	})
+
+	t.Run("stack", func(t *testing.T) {
+		logger, buf := newLogger(logs.ConsoleConfig{Pretty: true})
+		logger.E(NewStackError()).Error("test msg")
+
+		assert.NotContains(t, buf.String(), `"stack"`)
+		assert.Contains(t, buf.String(), "\nerror stack:\n\tgithub.com/rtkym/logs-go_test.TestConsoleConfig.func11\n\t\t")
+	})
+
+	t.Run("output", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger := logs.NewWithOption(logs.OptionOutput("stderr"), logs.OptionConsoleWriterWith(logs.ConsoleConfig{Out: buf}))
+		logger.Info("test msg")
+
+		assert.Contains(t, buf.String(), "info  test msg")
+	})
+
+	t.Run("sink", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger := logs.NewWithOption(logs.OptionSinks(logs.Sink{Format: "console", Writer: buf}))
+		logger.V("n", 1).Info("test msg")
+
+		assert.Regexp(t, `info  test msg \{n:1\}\n$`, buf.String())
+	})
+
+	t.Run("zerolog writer", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger := logs.NewWithOption(func(opt *logs.Option) {
+			opt.Writer = &zerolog.ConsoleWriter{Out: buf, NoColor: true, PartsExclude: []string{zerolog.TimestampFieldName}}
+		})
+		logger.V("n", 1).Info("test msg")
+
+		assert.Equal(t, "INF test msg n=1\n", buf.String())
+	})
+}
@@ -0,0 +1,219 @@
+package logs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// ECSVersion is the version of Elastic Common Schema output by the ecs format.
+const ECSVersion = "1.6.0"
+
+// newECSWriter returns a writer rendering zerolog's JSON lines as documents of Elastic Common Schema to out.
+//
+// The time, level and message are output as "@timestamp", "log.level" and "message", and the error added by E
+// as "error.message", "error.type" and "error.stack_trace". The caller, the logger name, the trace context and
+// the HTTP request are mapped to the ECS fields as well. Dotted keys, such as "http.request.method" set by V
+// or Set, are nested into objects.
+func newECSWriter(out io.Writer) *reshapeWriter {
+	return newReshapeWriter(out, ecsRecord)
+}
+
+// ecsRecord reshapes r into an ECS document.
+func ecsRecord(r record) record {
+	out := record{}
+
+	if value, ok := r.get(zerolog.TimestampFieldName); ok {
+		if t, ok := recordUnixTime(value); ok {
+			value = t.UTC().Format(time.RFC3339Nano)
+		}
+
+		out = out.set("@timestamp", value)
+	}
+
+	if value, ok := r.get(zerolog.LevelFieldName); ok {
+		out = putDotted(out, "log.level", value)
+	}
+
+	if value, ok := r.get(zerolog.MessageFieldName); ok {
+		out = out.set("message", value)
+	}
+
+	stack, hasStack := r.get(zerolog.ErrorStackFieldName)
+
+	for _, f := range r {
+		switch f.key {
+		case zerolog.TimestampFieldName, zerolog.LevelFieldName, zerolog.MessageFieldName, zerolog.ErrorStackFieldName:
+		case zerolog.CallerFieldName:
+			out = ecsCaller(out, f.value)
+		case CallerFuncFieldName:
+			out = putDotted(out, "log.origin.function", f.value)
+		case LoggerFieldName:
+			out = putDotted(out, "log.logger", f.value)
+		case TraceIDFieldName:
+			out = putDotted(out, "trace.id", f.value)
+		case SpanIDFieldName:
+			out = putDotted(out, "span.id", f.value)
+		case zerolog.ErrorFieldName:
+			out = ecsError(out, f.value, stack)
+			hasStack = false
+		case HTTPRequestFieldName:
+			out = ecsHTTPRequest(out, f.value)
+		default:
+			out = putDotted(out, f.key, f.value)
+		}
+	}
+
+	if frames, ok := stack.([]interface{}); hasStack && ok {
+		out = putDotted(out, "error.stack_trace", recordStackTrace(frames))
+	}
+
+	return putDotted(out, "ecs.version", ECSVersion)
+}
+
+// ecsCaller puts the caller of "file:line" as "log.origin.file.name" and "log.origin.file.line".
+func ecsCaller(out record, value interface{}) record {
+	caller, ok := value.(string)
+	if !ok {
+		return putDotted(out, "log.origin.file.name", value)
+	}
+
+	i := strings.LastIndexByte(caller, ':')
+	if i < 0 {
+		return putDotted(out, "log.origin.file.name", caller)
+	}
+
+	out = putDotted(out, "log.origin.file.name", caller[:i])
+
+	if _, err := strconv.Atoi(caller[i+1:]); err == nil {
+		out = putDotted(out, "log.origin.file.line", json.Number(caller[i+1:]))
+	}
+
+	return out
+}
+
+// ecsError puts the error object of E as "error.message", "error.type" and "error.stack_trace".
+// The stack of the error takes precedence over the stack of the message.
+// The other fields of the error, such as "fields" and "causes", are kept under "error".
+func ecsError(out record, value interface{}, stack interface{}) record {
+	errObject, ok := value.(record)
+	if !ok {
+		if value != nil {
+			out = putDotted(out, "error.message", fmt.Sprint(value))
+		}
+
+		if frames, ok := stack.([]interface{}); ok {
+			out = putDotted(out, "error.stack_trace", recordStackTrace(frames))
+		}
+
+		return out
+	}
+
+	if errStack, ok := errObject.get("stack"); ok {
+		stack = errStack
+	}
+
+	for _, f := range errObject {
+		if f.key != "stack" {
+			out = putDotted(out, "error."+f.key, f.value)
+		}
+	}
+
+	if frames, ok := stack.([]interface{}); ok {
+		out = putDotted(out, "error.stack_trace", recordStackTrace(frames))
+	}
+
+	return out
+}
+
+// ecsHTTPRequest puts the request added by LogEntry.HTTPRequest as the ECS fields of HTTP.
+func ecsHTTPRequest(out record, value interface{}) record {
+	req, ok := value.(record)
+	if !ok {
+		return putDotted(out, HTTPRequestFieldName, value)
+	}
+
+	for _, f := range req {
+		switch f.key {
+		case "requestMethod":
+			out = putDotted(out, "http.request.method", f.value)
+		case "requestUrl":
+			out = putDotted(out, "url.original", f.value)
+		case "status":
+			out = putDotted(out, "http.response.status_code", f.value)
+		case "requestSize":
+			out = putDotted(out, "http.request.body.bytes", ecsNumber(f.value))
+		case "responseSize":
+			out = putDotted(out, "http.response.body.bytes", ecsNumber(f.value))
+		case "userAgent":
+			out = putDotted(out, "user_agent.original", f.value)
+		case "remoteIp":
+			out = putDotted(out, "client.address", f.value)
+		case "serverIp":
+			out = putDotted(out, "server.address", f.value)
+		case "referer":
+			out = putDotted(out, "http.request.referrer", f.value)
+		case "latency":
+			if latency, ok := f.value.(string); ok {
+				if d, err := time.ParseDuration(latency); err == nil {
+					out = putDotted(out, "event.duration", json.Number(strconv.FormatInt(int64(d), 10)))
+				}
+			}
+		case "protocol":
+			if protocol, ok := f.value.(string); ok {
+				out = putDotted(out, "http.version", strings.TrimPrefix(protocol, "HTTP/"))
+			}
+		}
+	}
+
+	return out
+}
+
+// ecsNumber converts a string of an integer into a number.
+func ecsNumber(value interface{}) interface{} {
+	if s, ok := value.(string); ok {
+		if _, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return json.Number(s)
+		}
+	}
+
+	return value
+}
+
+// putDotted puts value at the dotted key, nesting it into objects such as {"a":{"b":value}} for "a.b".
+// Objects are merged with existing ones. If a part of the key is not an object, the key is kept as is.
+func putDotted(r record, key string, value interface{}) record {
+	name, rest, dotted := strings.Cut(key, ".")
+	if !dotted || name == "" || rest == "" {
+		if existing, ok := r.get(key); ok {
+			if existingObject, ok := existing.(record); ok {
+				if object, ok := value.(record); ok {
+					for _, f := range object {
+						existingObject = putDotted(existingObject, f.key, f.value)
+					}
+
+					return r.set(key, existingObject)
+				}
+			}
+		}
+
+		return r.set(key, value)
+	}
+
+	existing, ok := r.get(name)
+	if !ok {
+		return append(r, recordField{key: name, value: putDotted(record{}, rest, value)})
+	}
+
+	object, ok := existing.(record)
+	if !ok {
+		return r.set(key, value)
+	}
+
+	return r.set(name, putDotted(object, rest, value))
+}
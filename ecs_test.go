@@ -0,0 +1,134 @@
+package logs_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	logs "github.com/rtkym/logs-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestECSWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	newLogger := func(opts ...logs.OptionFunc) *logs.Logger {
+		return logs.NewWithOption(append([]logs.OptionFunc{
+			func(opt *logs.Option) { opt.Writer = logs.ExpNewFormatWriter("ecs", buf) },
+		}, opts...)...)
+	}
+
+	t.Run("base", func(t *testing.T) {
+		newLogger().Info("test msg")
+
+		line := buf.String()
+		assert.True(t, strings.HasPrefix(line, `{"@timestamp":"`), line)
+		assert.True(t, strings.HasSuffix(line, `"log":{"level":"info"},"message":"test msg","ecs":{"version":"1.6.0"}}`+"\n"), line)
+		buf.Reset()
+	})
+
+	t.Run("dotted keys", func(t *testing.T) {
+		logger := newLogger()
+		logger.Set("service.name", "api")
+		logger.Named("db").
+			V("http.request.method", "GET").
+			V("http", map[string]interface{}{"version": "1.1"}).
+			V("labels.env", "prod").
+			V("user.id", 1).
+			V("user.name", "alice").
+			V(".dot", "v").
+			Info("test msg")
+
+		res := decodeLine(t, buf)
+		assert.Equal(t, map[string]interface{}{"name": "api"}, res["service"])
+		assert.Equal(t, map[string]interface{}{"level": "info", "logger": "db"}, res["log"])
+		assert.Equal(t, map[string]interface{}{"request": map[string]interface{}{"method": "GET"}, "version": "1.1"}, res["http"])
+		assert.Equal(t, map[string]interface{}{"env": "prod"}, res["labels"])
+		assert.Equal(t, map[string]interface{}{"id": float64(1), "name": "alice"}, res["user"])
+		assert.Equal(t, "v", res[".dot"])
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		newLogger().V("user", "alice").V("user.id", 1).Info("test msg")
+
+		res := decodeLine(t, buf)
+		assert.Equal(t, "alice", res["user"])
+		assert.Equal(t, float64(1), res["user.id"])
+	})
+
+	t.Run("error", func(t *testing.T) {
+		err := fmt.Errorf("wrapped: %w", errors.New("test error"))
+		newLogger().E(err).Error("test msg")
+
+		res := decodeLine(t, buf)
+		assert.Equal(t, map[string]interface{}{
+			"message": "wrapped: test error",
+			"type":    "*fmt.wrapError",
+			"causes":  []interface{}{map[string]interface{}{"message": "test error", "type": "*errors.errorString"}},
+		}, res["error"])
+
+		newLogger().E(NewStackError()).Error("test msg")
+
+		res = decodeLine(t, buf)
+		errObject := res["error"].(map[string]interface{})
+		assert.Equal(t, "StackError", errObject["message"])
+		assert.Contains(t, errObject["stack_trace"], "TestECSWriter")
+		assert.Contains(t, errObject["stack_trace"], "ecs_test.go:")
+		assert.NotContains(t, errObject, "stack")
+	})
+
+	t.Run("stack", func(t *testing.T) {
+		newLogger(logs.OptionStacktraceLevel(logs.ErrorLevel)).Error("test msg")
+
+		res := decodeLine(t, buf)
+		assert.Contains(t, res["error"].(map[string]interface{})["stack_trace"], "ecs_test.go:")
+		assert.NotContains(t, res, "stack")
+	})
+
+	t.Run("caller and trace", func(t *testing.T) {
+		newLogger(logs.OptionCallerFunc()).Entry().TraceContext("trace-id", "span-id", true).Info("test msg")
+
+		res := decodeLine(t, buf)
+		origin := res["log"].(map[string]interface{})["origin"].(map[string]interface{})
+		file := origin["file"].(map[string]interface{})
+		assert.True(t, strings.HasSuffix(file["name"].(string), "ecs_test.go"), file)
+		assert.Greater(t, file["line"], float64(0))
+		assert.Equal(t, "github.com/rtkym/logs-go_test.TestECSWriter.func7", origin["function"])
+		assert.Equal(t, map[string]interface{}{"id": "trace-id"}, res["trace"])
+		assert.Equal(t, map[string]interface{}{"id": "span-id"}, res["span"])
+	})
+
+	t.Run("httpRequest", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/path", strings.NewReader("body"))
+		newLogger().Entry().HTTPRequest(logs.NewHTTPRequest(req, 201, 10, 1500*time.Millisecond)).Info("test msg")
+
+		res := decodeLine(t, buf)
+		assert.Equal(t, map[string]interface{}{
+			"request":  map[string]interface{}{"method": "POST", "body": map[string]interface{}{"bytes": float64(4)}},
+			"response": map[string]interface{}{"status_code": float64(201), "body": map[string]interface{}{"bytes": float64(10)}},
+			"version":  "1.1",
+		}, res["http"])
+		assert.Equal(t, map[string]interface{}{"original": "/path"}, res["url"])
+		assert.Equal(t, map[string]interface{}{"address": "192.0.2.1:1234"}, res["client"])
+		assert.Equal(t, map[string]interface{}{"duration": float64(1500000000)}, res["event"])
+	})
+
+	t.Run("OptionWriter,LOG_FORMAT", func(t *testing.T) {
+		outputs := formatOutputs(t, "ecs", func(logger *logs.Logger) { logger.V("user.id", 1).Info("test msg") })
+
+		for name, output := range outputs {
+			res := map[string]interface{}{}
+			assert.NoError(t, json.Unmarshal([]byte(output), &res), name)
+			assert.Equal(t, map[string]interface{}{"level": "info"}, res["log"], name)
+			assert.Equal(t, "test msg", res["message"], name)
+			assert.Equal(t, map[string]interface{}{"id": float64(1)}, res["user"], name)
+			assert.Equal(t, map[string]interface{}{"version": "1.6.0"}, res["ecs"], name)
+		}
+
+		assert.Len(t, outputs, 2)
+	})
+}
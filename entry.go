@@ -1,74 +1,380 @@
 package logs
 
 import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
 	"github.com/rs/zerolog"
 )
 
 // LogEntry is one record of logging.
+//
+// Attributes are output in the order they were added. If a key is added more than once,
+// the last value wins and is output at the position of the first.
+//
+// LogEntry is pooled and reused once a level method such as Info is called,
+// so it must not be used after that.
 type LogEntry struct {
 	logger *Logger
-	values map[string]interface{}
+	values []field
+
+	// skip is the number of frames of wrappers between the caller and the level method.
+	skip int
+
+	// pcs is the stack trace output instead of the caller's, such as of a recovered panic.
+	pcs []uintptr
+}
+
+// maxPooledFields is the largest capacity of LogEntry.values kept in entryPool.
+const maxPooledFields = 64
+
+// entryPool holds released LogEntry values.
+var entryPool = sync.Pool{ // nolint:gochecknoglobals
+	New: func() interface{} { return &LogEntry{} },
+}
+
+// newEntry returns a LogEntry of logger from entryPool.
+func newEntry(logger *Logger) *LogEntry {
+	x := entryPool.Get().(*LogEntry) // nolint:forcetypeassert
+	x.logger = logger
+
+	return x
+}
+
+// release resets x and returns it to entryPool.
+func (x *LogEntry) release() {
+	if cap(x.values) > maxPooledFields {
+		x.values = nil
+	} else {
+		for i := range x.values {
+			x.values[i] = field{}
+		}
+
+		x.values = x.values[:0]
+	}
+
+	x.logger = nil
+	x.skip = 0
+	x.pcs = nil
+	entryPool.Put(x)
+}
+
+// msg outputs the entry at level and releases it. Attributes are bound only if level is enabled.
+// skip is the number of frames between msg and the level method.
+func (x *LogEntry) msg(skip int, level zerolog.Level, msg string) {
+	logger := x.logger
+
+	if ev := logger.newEvent(level); ev != nil {
+		x.addCaller(ev, level, skip)
+		x.addStack(ev, level, skip)
+		x.bind(ev)
+		ev.Msg(msg)
+	}
+
+	x.release()
+
+	switch level { // nolint:exhaustive
+	case zerolog.FatalLevel:
+		logger.core.exit(1)
+	case zerolog.PanicLevel:
+		panic(msg)
+	}
+}
+
+// fieldKind is the type of value held by field.
+type fieldKind uint8
+
+const (
+	kindInterface fieldKind = iota
+	kindStr
+	kindInt
+	kindFloat
+	kindBool
+	kindDur
+	kindTime
+	kindBytes
+	kindStrs
+	kindStringer
+	kindError
+)
+
+// field is one key and value attribute of LogEntry.
+// Typed values are held in their own fields, so that they are output without reflection.
+type field struct {
+	key   string
+	kind  fieldKind
+	str   string
+	num   int64
+	float float64
+	time  time.Time
+	bytes []byte
+	strs  []string
+	value interface{}
 }
 
 func (x *LogEntry) bind(ev *zerolog.Event) {
-	for k, v := range x.values {
-		ev.Interface(k, v)
+	for i := range x.values {
+		f := &x.values[i]
+
+		switch f.kind {
+		case kindStr:
+			ev.Str(f.key, f.str)
+		case kindInt:
+			ev.Int64(f.key, f.num)
+		case kindFloat:
+			ev.Float64(f.key, f.float)
+		case kindBool:
+			ev.Bool(f.key, f.num != 0)
+		case kindDur:
+			ev.Dur(f.key, time.Duration(f.num))
+		case kindTime:
+			ev.Time(f.key, f.time)
+		case kindBytes:
+			ev.Bytes(f.key, f.bytes)
+		case kindStrs:
+			ev.Strs(f.key, f.strs)
+		case kindStringer:
+			stringer, _ := f.value.(fmt.Stringer)
+			ev.Stringer(f.key, stringer)
+		case kindError:
+			err, _ := f.value.(error)
+			ev.Interface(f.key, errorValue(err))
+		case kindInterface:
+			ev.Interface(f.key, f.value)
+		}
 	}
 }
 
+// add adds f to the attributes, replacing the value of the same key.
+func (x *LogEntry) add(f field) *LogEntry {
+	for i := range x.values {
+		if x.values[i].key == f.key {
+			x.values[i] = f
+
+			return x
+		}
+	}
+
+	x.values = append(x.values, f)
+
+	return x
+}
+
+// msgf outputs the entry at level with a message formatted only if level is enabled.
+func (x *LogEntry) msgf(level zerolog.Level, format string, args []interface{}) {
+	var msg string
+	if x.logger.level.Enabled(level) {
+		msg = fmt.Sprintf(format, args...)
+	}
+
+	x.msg(1, level, msg)
+}
+
+// msgw outputs the entry at level with key and value pairs.
+// A key which is not a string, or a key without value, is output with the key "!BADKEY" as KV.
+func (x *LogEntry) msgw(level zerolog.Level, msg string, keyvals []interface{}) {
+	x.KV(keyvals...)
+	x.msg(1, level, msg)
+}
+
 // Trace outputs messages at trace level.
 func (x *LogEntry) Trace(msg string) {
-	ev := x.logger.zeroLogger.Trace()
-	x.bind(ev)
-	ev.Msg(msg)
+	x.msg(0, zerolog.TraceLevel, msg)
 }
 
 // Debug outputs messages at debug level.
 func (x *LogEntry) Debug(msg string) {
-	ev := x.logger.zeroLogger.Debug()
-	x.bind(ev)
-	ev.Msg(msg)
+	x.msg(0, zerolog.DebugLevel, msg)
 }
 
 // Info outputs messages at info level.
 func (x *LogEntry) Info(msg string) {
-	ev := x.logger.zeroLogger.Info()
-	x.bind(ev)
-	ev.Msg(msg)
+	x.msg(0, zerolog.InfoLevel, msg)
 }
 
 // Warn outputs messages at warn level.
 func (x *LogEntry) Warn(msg string) {
-	ev := x.logger.zeroLogger.Warn()
-	x.bind(ev)
-	ev.Msg(msg)
+	x.msg(0, zerolog.WarnLevel, msg)
 }
 
 // Error outputs messages at error level.
 func (x *LogEntry) Error(msg string) {
-	ev := x.logger.zeroLogger.Error()
-	x.bind(ev)
-	ev.Msg(msg)
+	x.msg(0, zerolog.ErrorLevel, msg)
 }
 
-// Fatal outputs messages at fatal level.
+// Fatal outputs messages at fatal level, and then exits with status 1
+// after the exit hooks are called and the writer is flushed.
 func (x *LogEntry) Fatal(msg string) {
-	ev := x.logger.zeroLogger.Fatal()
-	x.bind(ev)
-	ev.Msg(msg)
+	x.msg(0, zerolog.FatalLevel, msg)
+}
+
+// Panic outputs messages at panic level, and then panics with the message.
+func (x *LogEntry) Panic(msg string) {
+	x.msg(0, zerolog.PanicLevel, msg)
+}
+
+// Tracef outputs messages formatted by fmt.Sprintf at trace level.
+func (x *LogEntry) Tracef(format string, args ...interface{}) {
+	x.msgf(zerolog.TraceLevel, format, args)
+}
+
+// Tracew outputs messages at trace level with key and value pairs.
+func (x *LogEntry) Tracew(msg string, keyvals ...interface{}) {
+	x.msgw(zerolog.TraceLevel, msg, keyvals)
+}
+
+// Debugf outputs messages formatted by fmt.Sprintf at debug level.
+func (x *LogEntry) Debugf(format string, args ...interface{}) {
+	x.msgf(zerolog.DebugLevel, format, args)
+}
+
+// Debugw outputs messages at debug level with key and value pairs.
+func (x *LogEntry) Debugw(msg string, keyvals ...interface{}) {
+	x.msgw(zerolog.DebugLevel, msg, keyvals)
+}
+
+// Infof outputs messages formatted by fmt.Sprintf at info level.
+func (x *LogEntry) Infof(format string, args ...interface{}) {
+	x.msgf(zerolog.InfoLevel, format, args)
+}
+
+// Infow outputs messages at info level with key and value pairs.
+func (x *LogEntry) Infow(msg string, keyvals ...interface{}) {
+	x.msgw(zerolog.InfoLevel, msg, keyvals)
+}
+
+// Warnf outputs messages formatted by fmt.Sprintf at warn level.
+func (x *LogEntry) Warnf(format string, args ...interface{}) {
+	x.msgf(zerolog.WarnLevel, format, args)
+}
+
+// Warnw outputs messages at warn level with key and value pairs.
+func (x *LogEntry) Warnw(msg string, keyvals ...interface{}) {
+	x.msgw(zerolog.WarnLevel, msg, keyvals)
+}
+
+// Errorf outputs messages formatted by fmt.Sprintf at error level.
+func (x *LogEntry) Errorf(format string, args ...interface{}) {
+	x.msgf(zerolog.ErrorLevel, format, args)
+}
+
+// Errorw outputs messages at error level with key and value pairs.
+func (x *LogEntry) Errorw(msg string, keyvals ...interface{}) {
+	x.msgw(zerolog.ErrorLevel, msg, keyvals)
+}
+
+// Fatalf outputs messages formatted by fmt.Sprintf at fatal level.
+func (x *LogEntry) Fatalf(format string, args ...interface{}) {
+	x.msgf(zerolog.FatalLevel, format, args)
+}
+
+// Fatalw outputs messages at fatal level with key and value pairs.
+func (x *LogEntry) Fatalw(msg string, keyvals ...interface{}) {
+	x.msgw(zerolog.FatalLevel, msg, keyvals)
+}
+
+// Panicf outputs messages formatted by fmt.Sprintf at panic level, and then panics with the message.
+func (x *LogEntry) Panicf(format string, args ...interface{}) {
+	x.msg(0, zerolog.PanicLevel, fmt.Sprintf(format, args...))
+}
+
+// Panicw outputs messages at panic level with key and value pairs, and then panics with the message.
+func (x *LogEntry) Panicw(msg string, keyvals ...interface{}) {
+	x.msgw(zerolog.PanicLevel, msg, keyvals)
 }
 
 // V adds key and value attribute to log message.
 func (x *LogEntry) V(key string, value interface{}) *LogEntry {
-	x.values[key] = value
+	return x.add(field{key: key, kind: kindInterface, value: value})
+}
+
+// KV adds key and value pairs to log message.
+// A key which is not a string, or a key without value, is output with the key "!BADKEY",
+// numbered as "!BADKEY1", "!BADKEY2" and so on if the message has more.
+func (x *LogEntry) KV(keyvals ...interface{}) *LogEntry {
+	bad := 0
+
+	for i := range x.values {
+		if strings.HasPrefix(x.values[i].key, badKey) {
+			bad++
+		}
+	}
+
+	forEachKeyval(keyvals, bad, func(key string, value interface{}) { x.V(key, value) })
+
 	return x
 }
 
+// Str adds string attribute to log message.
+func (x *LogEntry) Str(key string, value string) *LogEntry {
+	return x.add(field{key: key, kind: kindStr, str: value})
+}
+
+// Int adds int attribute to log message.
+func (x *LogEntry) Int(key string, value int) *LogEntry {
+	return x.add(field{key: key, kind: kindInt, num: int64(value)})
+}
+
+// Int64 adds int64 attribute to log message.
+func (x *LogEntry) Int64(key string, value int64) *LogEntry {
+	return x.add(field{key: key, kind: kindInt, num: value})
+}
+
+// Float adds float64 attribute to log message.
+func (x *LogEntry) Float(key string, value float64) *LogEntry {
+	return x.add(field{key: key, kind: kindFloat, float: value})
+}
+
+// Bool adds bool attribute to log message.
+func (x *LogEntry) Bool(key string, value bool) *LogEntry {
+	var num int64
+	if value {
+		num = 1
+	}
+
+	return x.add(field{key: key, kind: kindBool, num: num})
+}
+
+// Dur adds time.Duration attribute to log message. It is output in zerolog.DurationFieldUnit.
+func (x *LogEntry) Dur(key string, value time.Duration) *LogEntry {
+	return x.add(field{key: key, kind: kindDur, num: int64(value)})
+}
+
+// Time adds time.Time attribute to log message. It is output in zerolog.TimeFieldFormat.
+func (x *LogEntry) Time(key string, value time.Time) *LogEntry {
+	return x.add(field{key: key, kind: kindTime, time: value})
+}
+
+// Bytes adds []byte attribute to log message. It is output as a string.
+func (x *LogEntry) Bytes(key string, value []byte) *LogEntry {
+	return x.add(field{key: key, kind: kindBytes, bytes: value})
+}
+
+// Strs adds []string attribute to log message.
+func (x *LogEntry) Strs(key string, value []string) *LogEntry {
+	return x.add(field{key: key, kind: kindStrs, strs: value})
+}
+
+// Stringer adds the result of value.String() to log message. A nil value is output as null.
+func (x *LogEntry) Stringer(key string, value fmt.Stringer) *LogEntry {
+	return x.add(field{key: key, kind: kindStringer, value: value})
+}
+
 // E adds error attribute to log message.
+// The error is serialized only when the message is output. See EK for the format.
 func (x *LogEntry) E(err error) *LogEntry {
-	if _, ok := err.(interface{ MarshalJSON() ([]byte, error) }); ok { // nolint:errorlint
-		return x.V("error", err)
-	}
+	return x.EK(zerolog.ErrorFieldName, err)
+}
 
-	return x.V("error", map[string]string{"message": err.Error()})
+// EK adds error attribute to log message with key, so that an entry can have multiple errors.
+//
+// The error is serialized only when the message is output, as an object with "message" and
+// the Go "type" of the error. Errors implementing LogFielder add "fields". If the error carries
+// a stack trace, such as StackTracer and errors of github.com/pkg/errors, it is output as "stack".
+// Errors wrapped by the error, through Unwrap() error or Unwrap() []error, are output as "causes".
+// An error implementing json.Marshaler is output as it marshals itself.
+func (x *LogEntry) EK(key string, err error) *LogEntry {
+	return x.add(field{key: key, kind: kindError, value: err})
 }
@@ -0,0 +1,141 @@
+package logs
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/rs/zerolog"
+)
+
+// maxErrorDepth is the maximum depth of causes output for an error.
+const maxErrorDepth = 32
+
+// LogFielder is implemented by errors which supply structured context to log messages.
+type LogFielder interface {
+	LogFields() map[string]interface{}
+}
+
+// errorObject is the output of an error.
+type errorObject struct {
+	Message string                 `json:"message"`
+	Type    string                 `json:"type"`
+	Fields  map[string]interface{} `json:"fields,omitempty"`
+	Stack   []Frame                `json:"stack,omitempty"`
+	Causes  []*errorObject         `json:"causes,omitempty"`
+}
+
+// MarshalZerologObject implements zerolog.LogObjectMarshaler, so that the error is encoded natively
+// in CBOR of binary_log builds as well as in JSON.
+func (x *errorObject) MarshalZerologObject(e *zerolog.Event) {
+	e.Str("message", x.Message).Str("type", x.Type)
+
+	if len(x.Fields) > 0 {
+		keys := make([]string, 0, len(x.Fields))
+		for key := range x.Fields {
+			keys = append(keys, key)
+		}
+
+		sort.Strings(keys)
+
+		fields := zerolog.Dict()
+		for _, key := range keys {
+			fields.Interface(key, x.Fields[key])
+		}
+
+		e.Dict("fields", fields)
+	}
+
+	if len(x.Stack) > 0 {
+		e.Array("stack", frameArray(x.Stack))
+	}
+
+	if len(x.Causes) > 0 {
+		e.Array("causes", errorArray(x.Causes))
+	}
+}
+
+// errorArray is the output of causes.
+type errorArray []*errorObject
+
+// MarshalZerologArray implements zerolog.LogArrayMarshaler.
+func (x errorArray) MarshalZerologArray(a *zerolog.Array) {
+	for _, obj := range x {
+		a.Object(obj)
+	}
+}
+
+// errorValue returns the value output for err.
+func errorValue(err error) interface{} {
+	if err == nil {
+		return nil
+	}
+
+	if _, ok := err.(json.Marshaler); ok { // nolint:errorlint
+		return err
+	}
+
+	obj := newErrorObject(err, 0)
+	obj.Stack = errorStack(err)
+
+	return obj
+}
+
+// newErrorObject returns the output of err with its causes.
+func newErrorObject(err error, depth int) *errorObject {
+	obj := newErrorLayer(err)
+	obj.Causes = errorCauses(err, depth+1)
+
+	return obj
+}
+
+// newErrorLayer returns the output of err without its causes.
+func newErrorLayer(err error) *errorObject {
+	obj := &errorObject{
+		Message: err.Error(),
+		Type:    fmt.Sprintf("%T", err),
+	}
+
+	if fielder, ok := err.(LogFielder); ok { // nolint:errorlint
+		obj.Fields = fielder.LogFields()
+	}
+
+	return obj
+}
+
+// errorCauses returns the outputs of the errors wrapped by err.
+// A chain of Unwrap() error is flattened, and the errors of Unwrap() []error are nested in their parent.
+func errorCauses(err error, depth int) []*errorObject {
+	if depth > maxErrorDepth {
+		return nil
+	}
+
+	switch u := err.(type) { // nolint:errorlint
+	case interface{ Unwrap() []error }:
+		var causes []*errorObject
+
+		for _, cause := range u.Unwrap() {
+			if cause != nil {
+				causes = append(causes, newErrorObject(cause, depth))
+			}
+		}
+
+		return causes
+	case interface{ Unwrap() error }:
+		cause := u.Unwrap()
+		if cause == nil {
+			return nil
+		}
+
+		layer := newErrorLayer(cause)
+		if _, ok := cause.(interface{ Unwrap() []error }); ok { // nolint:errorlint
+			layer.Causes = errorCauses(cause, depth+1)
+
+			return []*errorObject{layer}
+		}
+
+		return append([]*errorObject{layer}, errorCauses(cause, depth+1)...)
+	}
+
+	return nil
+}
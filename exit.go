@@ -0,0 +1,47 @@
+package logs
+
+import (
+	"os"
+	"sync"
+)
+
+var (
+	exitHooksMu sync.Mutex // nolint:gochecknoglobals
+	exitHooks   []func()   // nolint:gochecknoglobals
+)
+
+// RegisterExitHook registers fn to be called before the process exits by Fatal.
+// Hooks are called in the order they were registered, before the writers of the logger are flushed.
+func RegisterExitHook(fn func()) {
+	exitHooksMu.Lock()
+	defer exitHooksMu.Unlock()
+
+	exitHooks = append(exitHooks, fn)
+}
+
+// OptionExitFunc returns an OptionFunc for configuring the function called by Fatal to exit.
+// The default is os.Exit.
+func OptionExitFunc(fn func(code int)) OptionFunc {
+	return func(opt *Option) {
+		opt.ExitFunc = fn
+	}
+}
+
+// exit runs the exit hooks, flushes the writer and exits with code.
+func (x *loggerCore) exit(code int) {
+	exitHooksMu.Lock()
+	hooks := append([]func(){}, exitHooks...)
+	exitHooksMu.Unlock()
+
+	for _, fn := range hooks {
+		fn()
+	}
+
+	_ = x.sync()
+
+	if x.exitFunc != nil {
+		x.exitFunc(code)
+	} else {
+		os.Exit(code)
+	}
+}
@@ -1,5 +1,11 @@
 package logs
 
+import (
+	"io"
+	"net/http"
+	"time"
+)
+
 func ExpSetLogLevel(s string) func() {
 	tmp := envLogLevel
 	envLogLevel = s
@@ -13,3 +19,106 @@ func ExpSetLogFormat(s string) func() {
 
 	return func() { envLogFormat = tmp }
 }
+
+func ExpSetLogCaller(s string) func() {
+	tmp := envLogCaller
+	envLogCaller = s
+
+	return func() { envLogCaller = tmp }
+}
+
+func ExpOptionCallerEnv() OptionFunc {
+	return optionCallerEnv(envLogCaller)
+}
+
+func ExpSetLogOutput(s string) func() {
+	tmp := envLogOutput
+	envLogOutput = s
+
+	return func() { envLogOutput = tmp }
+}
+
+func ExpSetMegabyte(n int64) func() {
+	tmp := megabyte
+	megabyte = n
+
+	return func() { megabyte = tmp }
+}
+
+func ExpSetLogSinks(s string) func() {
+	tmp := envLogSinks
+	envLogSinks = s
+
+	return func() { envLogSinks = tmp }
+}
+
+func ExpOptionSinksEnv(s string) OptionFunc {
+	return optionSinksEnv(s)
+}
+
+func ExpNewFormatWriter(name string, w io.Writer) io.Writer {
+	factory, err := lookupFormat(name)
+	if err != nil {
+		panic(err)
+	}
+
+	return factory(w)
+}
+
+func ExpNewScopeWriter(w io.Writer) io.Writer {
+	writer := newReshapeWriter(w, func(r record) record { return r })
+	writer.scope = true
+
+	return writer
+}
+
+func ExpSetOTELResource(attributes, serviceName string) func() {
+	tmpAttributes, tmpServiceName := envOTELResourceAttributes, envOTELServiceName
+	envOTELResourceAttributes, envOTELServiceName = attributes, serviceName
+
+	return func() { envOTELResourceAttributes, envOTELServiceName = tmpAttributes, tmpServiceName }
+}
+
+func ExpSetNoColor(s string) func() {
+	tmp := envNoColor
+	envNoColor = s
+
+	return func() { envNoColor = tmp }
+}
+
+func ExpConsoleColor(mode ColorMode, out io.Writer) bool {
+	return consoleColor(mode, out)
+}
+
+func ExpSetRename(rename func(string, string) error) func() {
+	tmp := osRename
+	osRename = rename
+
+	return func() { osRename = tmp }
+}
+
+func ExpLevelGeneration(h http.Handler) uint64 {
+	x := h.(*levelHandler)
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	return x.generation
+}
+
+func ExpRevertLevel(h http.Handler, generation uint64) {
+	h.(*levelHandler).revert(generation)
+}
+
+func ExpSharedFiles() int {
+	sharedFilesMu.Lock()
+	defer sharedFilesMu.Unlock()
+
+	return len(sharedFiles)
+}
+
+func ExpFileDeadline(w *FileWriter) time.Time {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.deadline
+}
@@ -0,0 +1,592 @@
+package logs
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrInvalidOutput is returned when a log output cannot be parsed.
+var ErrInvalidOutput = errors.New("invalid log output")
+
+// megabyte is the unit of RotateConfig.MaxSizeMB.
+var megabyte int64 = 1024 * 1024 // nolint:gochecknoglobals
+
+// backupTimeFormat is the format of the time in names of rotated files.
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+const compressSuffix = ".gz"
+
+// osRename renames rotated files. It is replaced in tests.
+var osRename = os.Rename // nolint:gochecknoglobals
+
+// RotateConfig is the configuration of rotation of FileWriter.
+type RotateConfig struct {
+	// MaxSizeMB is the maximum size in megabytes of the file before it is rotated. Zero disables size-based rotation.
+	MaxSizeMB int
+	// MaxAge is the maximum age of rotated files to retain. Zero retains them regardless of age.
+	MaxAge time.Duration
+	// MaxBackups is the maximum number of rotated files to retain. Zero retains all of them.
+	MaxBackups int
+	// Compress gzips rotated files.
+	Compress bool
+	// LocalTime uses the local time in names of rotated files and to align Interval instead of UTC.
+	LocalTime bool
+	// Interval is the period of time-based rotation, such as 24 * time.Hour. Zero disables time-based rotation.
+	Interval time.Duration
+	// ReopenOnSignal reopens the file on SIGHUP, so that it works with external tools such as logrotate.
+	// SIGHUP then no longer terminates the process. It is ignored on platforms without SIGHUP.
+	ReopenOnSignal bool
+}
+
+// FileWriter is an io.Writer which appends to a file and rotates it.
+//
+// A rotated file is renamed to "<name>-<time><ext>", such as "app-2006-01-02T15-04-05.000.log",
+// and compressed and removed in the background according to RotateConfig.
+// The file is reopened by Reopen, or on SIGHUP if RotateConfig.ReopenOnSignal is set.
+// It is safe for concurrent use.
+type FileWriter struct {
+	mu       sync.Mutex
+	path     string
+	config   RotateConfig
+	file     *os.File
+	size     int64
+	limit    int64
+	deadline time.Time
+	closed   bool
+
+	millCh chan struct{}
+	millWG sync.WaitGroup
+}
+
+// NewFileWriter opens the file at path for appending, creating it and its directory if needed.
+// The writer should be closed by Close, or Logger.Close when it is used by a logger.
+func NewFileWriter(path string, config RotateConfig) (*FileWriter, error) {
+	x := &FileWriter{
+		path:   path,
+		config: config,
+		millCh: make(chan struct{}, 1),
+	}
+
+	if err := x.open(); err != nil {
+		return nil, err
+	}
+
+	x.millWG.Add(1)
+
+	go x.mill()
+
+	// Apply MaxAge and MaxBackups to the files rotated before.
+	x.millCh <- struct{}{}
+
+	if config.ReopenOnSignal {
+		watchReopen(x)
+	}
+
+	return x, nil
+}
+
+// OptionFileWriter returns an OptionFunc for configuring the output to the file at path rotated by config.
+// The format set by OptionWriter is kept.
+// Loggers writing to the same file share one FileWriter, which is rotated by the config of the first of them.
+func OptionFileWriter(path string, config RotateConfig) OptionFunc {
+	return func(opt *Option) {
+		w, err := openSharedFile(path, config)
+		if err != nil {
+			opt.addError(err)
+
+			return
+		}
+
+		opt.setOutput(w)
+	}
+}
+
+// Write writes p to the file, rotating it before if it exceeds the size or the interval.
+// If the rotation fails, p is still written to the current file,
+// and the rotation is retried after the next step of the size or the interval.
+func (x *FileWriter) Write(p []byte) (int, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if x.closed {
+		return 0, os.ErrClosed
+	}
+
+	if x.file == nil {
+		if err := x.open(); err != nil {
+			return 0, err
+		}
+	} else if x.shouldRotate(len(p)) {
+		if err := x.rotate(); x.file == nil {
+			return 0, err
+		}
+	}
+
+	n, err := x.file.Write(p)
+	x.size += int64(n)
+
+	return n, err // nolint:wrapcheck
+}
+
+// Rotate rotates the file regardless of the size and the interval.
+func (x *FileWriter) Rotate() error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if x.closed {
+		return os.ErrClosed
+	}
+
+	return x.rotate()
+}
+
+// Reopen closes and reopens the file at the path.
+// It is called after the file is renamed by an external tool, such as on SIGHUP if RotateConfig.ReopenOnSignal is set.
+func (x *FileWriter) Reopen() error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if x.closed {
+		return os.ErrClosed
+	}
+
+	if x.file != nil {
+		err := x.file.Close()
+		x.file = nil
+
+		if err != nil {
+			return x.reopenAfter(err)
+		}
+	}
+
+	return x.open()
+}
+
+// Sync commits the file to the storage.
+func (x *FileWriter) Sync() error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if x.closed || x.file == nil {
+		return nil
+	}
+
+	return x.file.Sync() // nolint:wrapcheck
+}
+
+// Close closes the file and waits for the compression and removal of rotated files.
+func (x *FileWriter) Close() error {
+	x.mu.Lock()
+
+	if x.closed {
+		x.mu.Unlock()
+
+		return nil
+	}
+
+	x.closed = true
+
+	var err error
+	if x.file != nil {
+		err = x.file.Close()
+	}
+
+	close(x.millCh)
+	x.mu.Unlock()
+
+	unwatchReopen(x)
+	x.millWG.Wait()
+
+	return err // nolint:wrapcheck
+}
+
+// open opens the file at the path and resets the size, the limit and the deadline.
+func (x *FileWriter) open() error {
+	if err := os.MkdirAll(filepath.Dir(x.path), 0o755); err != nil { // nolint:gomnd
+		return err // nolint:wrapcheck
+	}
+
+	file, err := os.OpenFile(x.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644) // nolint:gomnd
+	if err != nil {
+		return err // nolint:wrapcheck
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close() // nolint:errcheck
+
+		return err // nolint:wrapcheck
+	}
+
+	x.file = file
+	x.size = info.Size()
+	x.limit = int64(x.config.MaxSizeMB) * megabyte
+
+	if x.config.Interval > 0 {
+		x.deadline = x.nextRotation(time.Now())
+	}
+
+	return nil
+}
+
+// nextRotation returns the time of the rotation by the interval after t.
+// The times are aligned to the interval in the location of names of rotated files,
+// so that a rotation every 24 hours with LocalTime is at the local midnight.
+func (x *FileWriter) nextRotation(t time.Time) time.Time {
+	var offset time.Duration
+
+	if x.config.LocalTime {
+		_, seconds := t.In(time.Local).Zone()
+		offset = time.Duration(seconds) * time.Second
+	}
+
+	return t.Add(offset).Truncate(x.config.Interval).Add(x.config.Interval - offset)
+}
+
+// reopenAfter opens the file at the path again after err, and returns err.
+func (x *FileWriter) reopenAfter(err error) error {
+	x.open() // nolint:errcheck
+
+	return err
+}
+
+// postpone raises the limit by the maximum size after a failed rotation, and returns err.
+// The deadline has been set to the next interval by open, so that the rotation is not retried by every Write.
+func (x *FileWriter) postpone(err error) error {
+	if x.limit > 0 {
+		x.limit = x.size + int64(x.config.MaxSizeMB)*megabyte
+	}
+
+	return err
+}
+
+// shouldRotate reports whether the file is rotated before writing n bytes.
+func (x *FileWriter) shouldRotate(n int) bool {
+	if x.limit > 0 && x.size > 0 && x.size+int64(n) > x.limit {
+		return true
+	}
+
+	return x.config.Interval > 0 && !time.Now().Before(x.deadline)
+}
+
+// rotate renames the file to a backup name, opens a new file and notifies the mill goroutine.
+// If the file cannot be renamed, it is reopened so that the writer keeps appending to it,
+// and the next rotation is postponed by postpone.
+// If no file can be opened, the file is left nil and opened again by the next Write.
+func (x *FileWriter) rotate() error {
+	if x.file != nil {
+		err := x.file.Close()
+		x.file = nil
+
+		if err != nil {
+			return x.postpone(x.reopenAfter(err))
+		}
+	}
+
+	if err := osRename(x.path, x.backupName(time.Now())); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return x.postpone(x.reopenAfter(err))
+	}
+
+	if err := x.open(); err != nil {
+		return err
+	}
+
+	select {
+	case x.millCh <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// backupName returns an unused name of the rotated file at t.
+func (x *FileWriter) backupName(t time.Time) string {
+	if !x.config.LocalTime {
+		t = t.UTC()
+	}
+
+	dir, prefix, ext := x.backupParts()
+
+	for {
+		name := filepath.Join(dir, prefix+t.Format(backupTimeFormat)+ext)
+
+		if !fileExists(name) && !fileExists(name+compressSuffix) {
+			return name
+		}
+
+		t = t.Add(time.Millisecond)
+	}
+}
+
+// backupParts returns the directory, the prefix and the extension of names of rotated files.
+func (x *FileWriter) backupParts() (string, string, string) {
+	dir, base := filepath.Split(x.path)
+	ext := filepath.Ext(base)
+
+	return dir, strings.TrimSuffix(base, ext) + "-", ext
+}
+
+// mill compresses and removes rotated files whenever the file is rotated, until the writer is closed.
+func (x *FileWriter) mill() {
+	defer x.millWG.Done()
+
+	for range x.millCh {
+		x.millOnce()
+	}
+}
+
+// backupFile is a rotated file.
+type backupFile struct {
+	path string
+	time time.Time
+}
+
+// millOnce removes the rotated files exceeding MaxBackups or MaxAge, and compresses the rest if Compress is set.
+// Errors are ignored as there is nowhere to report them, and the files are retried at the next rotation.
+func (x *FileWriter) millOnce() {
+	backups := x.backups()
+
+	var cutoff time.Time
+	if x.config.MaxAge > 0 {
+		cutoff = time.Now().Add(-x.config.MaxAge)
+	}
+
+	for i, backup := range backups {
+		if (x.config.MaxBackups > 0 && i >= x.config.MaxBackups) || backup.time.Before(cutoff) {
+			os.Remove(backup.path) // nolint:errcheck
+
+			continue
+		}
+
+		if x.config.Compress && !strings.HasSuffix(backup.path, compressSuffix) {
+			compressFile(backup.path) // nolint:errcheck
+		}
+	}
+}
+
+// backups returns the rotated files sorted from the newest.
+func (x *FileWriter) backups() []backupFile {
+	dir, prefix, ext := x.backupParts()
+
+	entries, err := os.ReadDir(filepath.Clean(dir))
+	if err != nil {
+		return nil
+	}
+
+	location := time.UTC
+	if x.config.LocalTime {
+		location = time.Local
+	}
+
+	var backups []backupFile
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), compressSuffix)
+		if !strings.HasSuffix(stamp, ext) {
+			continue
+		}
+
+		t, err := time.ParseInLocation(backupTimeFormat, strings.TrimSuffix(stamp, ext), location)
+		if err != nil {
+			continue
+		}
+
+		backups = append(backups, backupFile{path: filepath.Join(dir, name), time: t})
+	}
+
+	sort.Slice(backups, func(i, j int) bool { return backups[i].time.After(backups[j].time) })
+
+	return backups
+}
+
+// compressFile gzips the file at path to path.gz and removes the original.
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err // nolint:wrapcheck
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+compressSuffix, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644) // nolint:gomnd
+	if err != nil {
+		return err // nolint:wrapcheck
+	}
+
+	gz := gzip.NewWriter(dst)
+
+	if _, err := io.Copy(gz, src); err != nil {
+		dst.Close()                      // nolint:errcheck
+		os.Remove(path + compressSuffix) // nolint:errcheck
+
+		return err // nolint:wrapcheck
+	}
+
+	if err := gz.Close(); err != nil {
+		dst.Close()                      // nolint:errcheck
+		os.Remove(path + compressSuffix) // nolint:errcheck
+
+		return err // nolint:wrapcheck
+	}
+
+	if err := dst.Close(); err != nil {
+		os.Remove(path + compressSuffix) // nolint:errcheck
+
+		return err // nolint:wrapcheck
+	}
+
+	src.Close() // nolint:errcheck
+
+	return os.Remove(path) // nolint:wrapcheck
+}
+
+// fileExists reports whether the file at path exists.
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+
+	return err == nil
+}
+
+// parseFileURL parses a file URL such as "file:///var/log/app.log?max_size_mb=100&compress=true".
+func parseFileURL(output string) (string, RotateConfig, error) {
+	var config RotateConfig
+
+	u, err := url.Parse(output)
+	if err != nil || u.Scheme != "file" {
+		return "", config, fmt.Errorf("%w: %q", ErrInvalidOutput, output)
+	}
+
+	path := u.Opaque
+	if path == "" {
+		path = u.Host + u.Path
+	}
+
+	if path == "" {
+		return "", config, fmt.Errorf("%w: %q has no path", ErrInvalidOutput, output)
+	}
+
+	for key, values := range u.Query() {
+		value := values[len(values)-1]
+
+		switch key {
+		case "max_size_mb":
+			config.MaxSizeMB, err = strconv.Atoi(value)
+		case "max_age":
+			config.MaxAge, err = time.ParseDuration(value)
+		case "max_backups":
+			config.MaxBackups, err = strconv.Atoi(value)
+		case "compress":
+			config.Compress, err = strconv.ParseBool(value)
+		case "local_time":
+			config.LocalTime, err = strconv.ParseBool(value)
+		case "interval":
+			config.Interval, err = time.ParseDuration(value)
+		case "reopen_on_signal":
+			config.ReopenOnSignal, err = strconv.ParseBool(value)
+		default:
+			err = errors.New("unknown parameter")
+		}
+
+		if err != nil {
+			return "", config, fmt.Errorf("%w: %q: %s: %v", ErrInvalidOutput, output, key, err) // nolint:errorlint
+		}
+	}
+
+	return path, config, nil
+}
+
+// nolint:gochecknoglobals
+var (
+	sharedFilesMu sync.Mutex
+	sharedFiles   = map[string]*sharedFile{}
+)
+
+// sharedFile is a FileWriter shared by the outputs to the same file, and the number of the outputs not closed yet.
+type sharedFile struct {
+	writer *FileWriter
+	refs   int
+}
+
+// sharedFileWriter is an output to a shared FileWriter, which is closed when all the outputs to it are closed.
+type sharedFileWriter struct {
+	key    string
+	shared *sharedFile
+	closed int32
+}
+
+// openSharedFile returns an output to the FileWriter of the file at path, opening it by config unless it is already open.
+// Loggers are often created for each request, such as by New, and would otherwise open the file every time.
+func openSharedFile(path string, config RotateConfig) (*sharedFileWriter, error) {
+	key, err := filepath.Abs(path)
+	if err != nil {
+		key = filepath.Clean(path)
+	}
+
+	sharedFilesMu.Lock()
+	defer sharedFilesMu.Unlock()
+
+	shared, ok := sharedFiles[key]
+	if !ok {
+		w, err := NewFileWriter(path, config)
+		if err != nil {
+			return nil, err
+		}
+
+		shared = &sharedFile{writer: w}
+		sharedFiles[key] = shared
+	}
+
+	shared.refs++
+
+	return &sharedFileWriter{key: key, shared: shared}, nil
+}
+
+// Write writes p to the shared FileWriter.
+func (x *sharedFileWriter) Write(p []byte) (int, error) {
+	if atomic.LoadInt32(&x.closed) != 0 {
+		return 0, os.ErrClosed
+	}
+
+	return x.shared.writer.Write(p)
+}
+
+// Sync commits the shared FileWriter to the storage.
+func (x *sharedFileWriter) Sync() error {
+	return x.shared.writer.Sync()
+}
+
+// Close closes the output, and the shared FileWriter if it is the last output to it.
+func (x *sharedFileWriter) Close() error {
+	if !atomic.CompareAndSwapInt32(&x.closed, 0, 1) {
+		return nil
+	}
+
+	sharedFilesMu.Lock()
+
+	x.shared.refs--
+	if x.shared.refs > 0 {
+		sharedFilesMu.Unlock()
+
+		return nil
+	}
+
+	delete(sharedFiles, x.key)
+	sharedFilesMu.Unlock()
+
+	return x.shared.writer.Close()
+}
@@ -0,0 +1,10 @@
+//go:build js
+// +build js
+
+package logs
+
+// watchReopen does nothing on platforms without SIGHUP.
+func watchReopen(*FileWriter) {}
+
+// unwatchReopen does nothing on platforms without SIGHUP.
+func unwatchReopen(*FileWriter) {}
@@ -0,0 +1,55 @@
+//go:build !js
+// +build !js
+
+package logs
+
+import (
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// nolint:gochecknoglobals
+var (
+	reopenMu      sync.Mutex
+	reopenWriters = map[*FileWriter]struct{}{}
+	reopenOnce    sync.Once
+)
+
+// watchReopen registers x to be reopened on SIGHUP.
+// The signal is handled once the first FileWriter with RotateConfig.ReopenOnSignal is created,
+// so that it no longer terminates the process.
+func watchReopen(x *FileWriter) {
+	reopenMu.Lock()
+	reopenWriters[x] = struct{}{}
+	reopenMu.Unlock()
+
+	reopenOnce.Do(func() {
+		ch := make(chan os.Signal, 1)
+		signal.Notify(ch, syscall.SIGHUP)
+
+		go func() {
+			for range ch {
+				reopenAll()
+			}
+		}()
+	})
+}
+
+// unwatchReopen unregisters x.
+func unwatchReopen(x *FileWriter) {
+	reopenMu.Lock()
+	delete(reopenWriters, x)
+	reopenMu.Unlock()
+}
+
+// reopenAll reopens the registered writers.
+func reopenAll() {
+	reopenMu.Lock()
+	defer reopenMu.Unlock()
+
+	for x := range reopenWriters {
+		x.Reopen() // nolint:errcheck
+	}
+}
@@ -0,0 +1,50 @@
+//go:build !js
+// +build !js
+
+package logs_test
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	logs "github.com/rtkym/logs-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileWriterSignal(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	other := filepath.Join(dir, "other.log")
+
+	w, err := logs.NewFileWriter(path, logs.RotateConfig{ReopenOnSignal: true})
+	assert.NoError(t, err)
+
+	defer w.Close()
+
+	ow, err := logs.NewFileWriter(other, logs.RotateConfig{})
+	assert.NoError(t, err)
+
+	defer ow.Close()
+
+	p, err := os.FindProcess(os.Getpid())
+	assert.NoError(t, err)
+
+	assert.NoError(t, os.Rename(path, path+".1"))
+	assert.NoError(t, os.Rename(other, other+".1"))
+
+	if err := p.Signal(syscall.SIGHUP); err != nil {
+		t.Skip("SIGHUP is not supported:", err)
+	}
+
+	assert.Eventually(t, func() bool {
+		_, err := os.Stat(path)
+
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+
+	_, err = os.Stat(other)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -0,0 +1,390 @@
+package logs_test
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	logs "github.com/rtkym/logs-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func readDir(t *testing.T, dir string) []string {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+
+	b, err := os.ReadFile(path)
+	assert.NoError(t, err)
+
+	return string(b)
+}
+
+func readGzip(t *testing.T, path string) string {
+	t.Helper()
+
+	f, err := os.Open(path)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	r, err := gzip.NewReader(f)
+	assert.NoError(t, err)
+
+	b, err := io.ReadAll(r)
+	assert.NoError(t, err)
+
+	return string(b)
+}
+
+func TestFileWriter(t *testing.T) {
+	defer logs.ExpSetMegabyte(10)()
+
+	t.Run("write", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "sub", "app.log")
+
+		w, err := logs.NewFileWriter(path, logs.RotateConfig{})
+		assert.NoError(t, err)
+
+		_, err = w.Write([]byte("hello\n"))
+		assert.NoError(t, err)
+		assert.NoError(t, w.Sync())
+		assert.NoError(t, w.Close())
+		assert.NoError(t, w.Close())
+
+		assert.Equal(t, "hello\n", readFile(t, path))
+
+		_, err = w.Write([]byte("closed\n"))
+		assert.ErrorIs(t, err, os.ErrClosed)
+	})
+
+	t.Run("size", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "app.log")
+
+		w, err := logs.NewFileWriter(path, logs.RotateConfig{MaxSizeMB: 1})
+		assert.NoError(t, err)
+
+		for _, s := range []string{"first\n", "second\n", "third\n"} {
+			_, err = w.Write([]byte(s))
+			assert.NoError(t, err)
+		}
+
+		assert.NoError(t, w.Close())
+
+		names := readDir(t, dir)
+		assert.Len(t, names, 3)
+		assert.Equal(t, "app.log", names[2])
+		assert.Equal(t, "third\n", readFile(t, path))
+		assert.Equal(t, "first\n", readFile(t, filepath.Join(dir, names[0])))
+		assert.Equal(t, "second\n", readFile(t, filepath.Join(dir, names[1])))
+
+		for _, name := range names[:2] {
+			assert.True(t, strings.HasPrefix(name, "app-"), name)
+			assert.True(t, strings.HasSuffix(name, ".log"), name)
+		}
+	})
+
+	t.Run("interval", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "app.log")
+
+		w, err := logs.NewFileWriter(path, logs.RotateConfig{Interval: 50 * time.Millisecond})
+		assert.NoError(t, err)
+
+		_, err = w.Write([]byte("first\n"))
+		assert.NoError(t, err)
+
+		time.Sleep(100 * time.Millisecond)
+
+		_, err = w.Write([]byte("second\n"))
+		assert.NoError(t, err)
+		assert.NoError(t, w.Close())
+
+		assert.Len(t, readDir(t, dir), 2)
+		assert.Equal(t, "second\n", readFile(t, path))
+	})
+
+	t.Run("compress and max backups", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "app.log")
+
+		w, err := logs.NewFileWriter(path, logs.RotateConfig{MaxBackups: 2, Compress: true})
+		assert.NoError(t, err)
+
+		for _, s := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+			_, err = w.Write([]byte(s))
+			assert.NoError(t, err)
+			assert.NoError(t, w.Rotate())
+		}
+
+		assert.NoError(t, w.Close())
+
+		names := readDir(t, dir)
+		assert.Len(t, names, 3)
+		assert.Equal(t, "app.log", names[2])
+		assert.Equal(t, "", readFile(t, path))
+
+		for _, name := range names[:2] {
+			assert.True(t, strings.HasSuffix(name, ".log.gz"), name)
+		}
+
+		assert.Equal(t, "third\n", readGzip(t, filepath.Join(dir, names[0])))
+		assert.Equal(t, "fourth\n", readGzip(t, filepath.Join(dir, names[1])))
+	})
+
+	t.Run("max age", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "app.log")
+		old := filepath.Join(dir, "app-2000-01-01T00-00-00.000.log")
+		other := filepath.Join(dir, "other-2000-01-01T00-00-00.000.log")
+
+		assert.NoError(t, os.WriteFile(old, []byte("old\n"), 0o600))
+		assert.NoError(t, os.WriteFile(other, []byte("other\n"), 0o600))
+
+		w, err := logs.NewFileWriter(path, logs.RotateConfig{MaxAge: time.Hour})
+		assert.NoError(t, err)
+
+		_, err = w.Write([]byte("new\n"))
+		assert.NoError(t, err)
+		assert.NoError(t, w.Rotate())
+		assert.NoError(t, w.Close())
+
+		names := readDir(t, dir)
+		assert.Len(t, names, 3)
+		assert.NotContains(t, names, filepath.Base(old))
+		assert.Contains(t, names, filepath.Base(other))
+	})
+
+	t.Run("max backups at start", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "app.log")
+
+		for _, name := range []string{"app-2000-01-01T00-00-00.000.log", "app-2000-01-02T00-00-00.000.log", "app-2000-01-03T00-00-00.000.log"} {
+			assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(name), 0o600))
+		}
+
+		w, err := logs.NewFileWriter(path, logs.RotateConfig{MaxBackups: 1})
+		assert.NoError(t, err)
+		assert.NoError(t, w.Close())
+
+		assert.Equal(t, []string{"app-2000-01-03T00-00-00.000.log", "app.log"}, readDir(t, dir))
+	})
+
+	t.Run("rename failure", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "app.log")
+		errRename := errors.New("rename failed")
+		renames := 0
+
+		w, err := logs.NewFileWriter(path, logs.RotateConfig{MaxSizeMB: 1})
+		assert.NoError(t, err)
+
+		_, err = w.Write([]byte("first\n"))
+		assert.NoError(t, err)
+
+		restore := logs.ExpSetRename(func(string, string) error {
+			renames++
+
+			return errRename
+		})
+
+		for _, s := range []string{"second\n", "a\n"} {
+			n, err := w.Write([]byte(s))
+			assert.NoError(t, err)
+			assert.Equal(t, len(s), n)
+		}
+
+		restore()
+
+		assert.Equal(t, 1, renames)
+
+		_, err = w.Write([]byte("third\n"))
+		assert.NoError(t, err)
+		assert.NoError(t, w.Close())
+
+		names := readDir(t, dir)
+		assert.Len(t, names, 2)
+		assert.Equal(t, "first\nsecond\na\n", readFile(t, filepath.Join(dir, names[0])))
+		assert.Equal(t, "third\n", readFile(t, path))
+	})
+
+	t.Run("local time", func(t *testing.T) {
+		defer func(local *time.Location) { time.Local = local }(time.Local)
+
+		time.Local = time.FixedZone("UTC+9", 9*60*60)
+
+		for _, tt := range []struct {
+			localTime bool
+			location  *time.Location
+		}{
+			{false, time.UTC},
+			{true, time.Local},
+		} {
+			w, err := logs.NewFileWriter(filepath.Join(t.TempDir(), "app.log"), logs.RotateConfig{Interval: 24 * time.Hour, LocalTime: tt.localTime})
+			assert.NoError(t, err)
+
+			now := time.Now().In(tt.location)
+			assert.Equal(t, time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, tt.location).Unix(), logs.ExpFileDeadline(w).Unix())
+			assert.NoError(t, w.Close())
+		}
+	})
+
+	t.Run("open failure", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "app.log")
+
+		w, err := logs.NewFileWriter(path, logs.RotateConfig{})
+		assert.NoError(t, err)
+
+		_, err = w.Write([]byte("first\n"))
+		assert.NoError(t, err)
+
+		// A directory at the path cannot be opened for writing.
+		assert.NoError(t, os.Remove(path))
+		assert.NoError(t, os.Mkdir(path, 0o755))
+		assert.Error(t, w.Reopen())
+		assert.NoError(t, w.Sync())
+
+		_, err = w.Write([]byte("lost\n"))
+		assert.Error(t, err)
+
+		assert.NoError(t, os.Remove(path))
+
+		_, err = w.Write([]byte("second\n"))
+		assert.NoError(t, err)
+		assert.NoError(t, w.Close())
+
+		assert.Equal(t, "second\n", readFile(t, path))
+	})
+
+	t.Run("reopen", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "app.log")
+		moved := filepath.Join(dir, "app.log.1")
+
+		w, err := logs.NewFileWriter(path, logs.RotateConfig{})
+		assert.NoError(t, err)
+
+		_, err = w.Write([]byte("first\n"))
+		assert.NoError(t, err)
+		assert.NoError(t, os.Rename(path, moved))
+
+		_, err = w.Write([]byte("second\n"))
+		assert.NoError(t, err)
+		assert.NoError(t, w.Reopen())
+
+		_, err = w.Write([]byte("third\n"))
+		assert.NoError(t, err)
+		assert.NoError(t, w.Close())
+
+		assert.Equal(t, "first\nsecond\n", readFile(t, moved))
+		assert.Equal(t, "third\n", readFile(t, path))
+	})
+
+}
+
+func TestOptionFileWriter(t *testing.T) {
+	t.Run("option", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.log")
+
+		logger, err := logs.NewWithOptionE(logs.OptionFileWriter(path, logs.RotateConfig{}))
+		assert.NoError(t, err)
+
+		logger.Info("hello")
+		assert.NoError(t, logger.Close())
+
+		assert.Contains(t, readFile(t, path), `{"level":"info",`)
+		assert.Contains(t, readFile(t, path), `"message":"hello"}`)
+	})
+
+	t.Run("format", func(t *testing.T) {
+		for _, opts := range [][]logs.OptionFunc{
+			{logs.OptionOutput("file://" + filepath.Join(t.TempDir(), "app.log")), logs.OptionWriter("console")},
+			{logs.OptionWriter("console"), logs.OptionOutput("file://" + filepath.Join(t.TempDir(), "app.log"))},
+		} {
+			opt := &logs.Option{}
+			for _, fn := range opts {
+				fn(opt)
+			}
+
+			writer, ok := opt.Writer.(*zerolog.ConsoleWriter)
+			if assert.True(t, ok) {
+				assert.NotEqual(t, os.Stdout, writer.Out)
+				assert.NoError(t, writer.Out.(io.Closer).Close())
+			}
+		}
+	})
+
+	t.Run("LOG_OUTPUT", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.log")
+		defer logs.ExpSetLogOutput("file://" + path + "?max_size_mb=100&compress=true")()
+
+		logger := logs.New()
+		logger.Info("hello")
+		assert.NoError(t, logger.Close())
+
+		assert.Contains(t, readFile(t, path), `"message":"hello"`)
+	})
+
+	t.Run("shared", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "app.log")
+		defer logs.ExpSetLogOutput("file://" + path)()
+
+		loggers := make([]*logs.Logger, 10)
+		for i := range loggers {
+			loggers[i] = logs.New()
+		}
+
+		assert.Equal(t, 1, logs.ExpSharedFiles())
+
+		sink, err := logs.NewWithOptionE(logs.OptionSinks(logs.Sink{Output: "file://" + path}))
+		assert.NoError(t, err)
+		assert.Equal(t, 1, logs.ExpSharedFiles())
+
+		for i, logger := range loggers {
+			logger.Infof("logger %d", i)
+			assert.NoError(t, logger.Close())
+			assert.NoError(t, logger.Close())
+		}
+
+		sink.Info("sink")
+		assert.NoError(t, sink.Close())
+		assert.Equal(t, 0, logs.ExpSharedFiles())
+
+		assert.Equal(t, 11, strings.Count(readFile(t, path), "\n"))
+		assert.Contains(t, readFile(t, path), `"message":"sink"`)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, output := range []string{
+			"http://example.com/app.log",
+			"file://",
+			"file:///tmp/app.log?max_size_mb=x",
+			"file:///tmp/app.log?unknown=1",
+		} {
+			_, err := logs.NewWithOptionE(logs.OptionOutput(output))
+			assert.ErrorIs(t, err, logs.ErrInvalidOutput, output)
+		}
+	})
+}
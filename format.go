@@ -0,0 +1,98 @@
+package logs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrUnknownFormat is returned when a format name is not registered.
+var ErrUnknownFormat = errors.New("unknown log format")
+
+// FormatFactory wraps the output writer with a writer that renders zerolog's JSON lines in some format.
+type FormatFactory func(w io.Writer) io.Writer
+
+var (
+	formatsMu sync.RWMutex // nolint:gochecknoglobals
+
+	// formats holds the registered formats by lower-case name.
+	formats = builtinFormats() // nolint:gochecknoglobals
+)
+
+// builtinFormats returns the formats provided by this package.
+// The cbor format, which writes zerolog's output as is, is provided only in binary_log builds.
+func builtinFormats() map[string]FormatFactory {
+	builtin := map[string]FormatFactory{
+		"json":      jsonFormat,
+		"console":   func(w io.Writer) io.Writer { return newConsoleWriter(w) },
+		"logfmt":    func(w io.Writer) io.Writer { return newLogfmtWriter(w) },
+		"gcp":       func(w io.Writer) io.Writer { return newGCPWriter(w) },
+		"ecs":       func(w io.Writer) io.Writer { return newECSWriter(w) },
+		"otlp-json": func(w io.Writer) io.Writer { return newOTLPWriter(w) },
+	}
+
+	if binaryLog {
+		builtin["cbor"] = func(w io.Writer) io.Writer { return w }
+	}
+
+	return builtin
+}
+
+// jsonFormat returns w as is, or wraps it to convert CBOR into JSON in binary_log builds.
+func jsonFormat(w io.Writer) io.Writer {
+	if binaryLog {
+		return &cborJSONWriter{out: w}
+	}
+
+	return w
+}
+
+// RegisterFormat makes a format available by name to OptionWriter and LOG_FORMAT.
+// Names are case-insensitive. Registering an existing name replaces it.
+// In binary_log builds, the writer of the format receives the records converted from CBOR into JSON lines.
+func RegisterFormat(name string, factory func(io.Writer) io.Writer) {
+	if factory == nil {
+		panic("logs: RegisterFormat factory is nil")
+	}
+
+	if binaryLog {
+		jsonFactory := factory
+		factory = func(w io.Writer) io.Writer { return jsonFormat(jsonFactory(w)) }
+	}
+
+	formatsMu.Lock()
+	defer formatsMu.Unlock()
+
+	formats[strings.ToLower(name)] = factory
+}
+
+// Formats returns the sorted names of the registered formats.
+func Formats() []string {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+
+	names := make([]string, 0, len(formats))
+	for name := range formats {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// lookupFormat returns the factory registered as name.
+func lookupFormat(name string) (FormatFactory, error) {
+	formatsMu.RLock()
+	defer formatsMu.RUnlock()
+
+	factory, ok := formats[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownFormat, name)
+	}
+
+	return factory, nil
+}
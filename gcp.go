@@ -0,0 +1,215 @@
+package logs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// GCPProjectID is the project of traces output by the gcp format. The default is GOOGLE_CLOUD_PROJECT.
+var GCPProjectID = os.Getenv("GOOGLE_CLOUD_PROJECT") // nolint:gochecknoglobals
+
+// Fields of the structured logging of Cloud Logging.
+const (
+	gcpSeverityField       = "severity"
+	gcpTimestampField      = "timestamp"
+	gcpTraceField          = "logging.googleapis.com/trace"
+	gcpSpanIDField         = "logging.googleapis.com/spanId"
+	gcpTraceSampledField   = "logging.googleapis.com/trace_sampled"
+	gcpSourceLocationField = "logging.googleapis.com/sourceLocation"
+
+	gcpReportedErrorEvent = "type.googleapis.com/google.devtools.clouderrorreporting.v1beta1.ReportedErrorEvent"
+)
+
+// errorReporter is implemented by writers which report errors, such as to Error Reporting.
+// They need the caller of messages at error level and above with an error added by E as zerolog.ErrorFieldName,
+// which is added by the logger even if caller annotation is disabled.
+type errorReporter interface {
+	reportsErrors() bool
+}
+
+// wantsErrorCaller reports whether w needs the caller of errors.
+func wantsErrorCaller(w io.Writer) bool {
+	writer, ok := w.(errorReporter)
+
+	return ok && writer.reportsErrors()
+}
+
+// newGCPWriter returns a writer rendering zerolog's JSON lines as the structured logging of Google Cloud Logging
+// to out.
+//
+// The level is output as "severity", the time as "timestamp", the caller as "logging.googleapis.com/sourceLocation",
+// and the trace context added by LogEntry.TraceContext as "logging.googleapis.com/trace" and "spanId".
+// Messages at error and above with an error added by E are marked as ReportedErrorEvent for Error Reporting.
+func newGCPWriter(out io.Writer) *reshapeWriter {
+	writer := newReshapeWriter(out, gcpRecord)
+	writer.errorCaller = true
+
+	return writer
+}
+
+// gcpRecord reshapes r into the structured logging of Cloud Logging.
+func gcpRecord(r record) record {
+	level := zerolog.NoLevel
+	if s, ok := r.getString(zerolog.LevelFieldName); ok {
+		level, _ = zerolog.ParseLevel(s)
+	}
+
+	out := record{{key: gcpSeverityField, value: gcpSeverity(level)}}
+
+	for _, f := range r {
+		switch f.key {
+		case zerolog.LevelFieldName, CallerFuncFieldName:
+		case zerolog.TimestampFieldName:
+			out = append(out, recordField{key: gcpTimestampField, value: gcpTimestamp(f.value)})
+		case TraceIDFieldName:
+			out = append(out, recordField{key: gcpTraceField, value: gcpTrace(f.value)})
+		case SpanIDFieldName:
+			out = append(out, recordField{key: gcpSpanIDField, value: f.value})
+		case TraceSampledFieldName:
+			out = append(out, recordField{key: gcpTraceSampledField, value: f.value})
+		case zerolog.CallerFieldName:
+			if location := gcpSourceLocation(r); location != nil {
+				out = append(out, recordField{key: gcpSourceLocationField, value: location})
+			}
+		default:
+			out = append(out, f)
+		}
+	}
+
+	if level >= ErrorLevel && level <= PanicLevel {
+		out = gcpReportError(out, r)
+	}
+
+	return out
+}
+
+// gcpSeverity returns the severity of level.
+func gcpSeverity(level Level) string {
+	switch level {
+	case TraceLevel, DebugLevel:
+		return "DEBUG"
+	case InfoLevel:
+		return "INFO"
+	case WarnLevel:
+		return "WARNING"
+	case ErrorLevel:
+		return "ERROR"
+	case FatalLevel, PanicLevel:
+		return "CRITICAL"
+	}
+
+	return "DEFAULT"
+}
+
+// gcpTimestamp returns the time as is if it is a string such as RFC3339,
+// or converts the UNIX time of zerolog.TimeFieldFormat into an object of seconds and nanos.
+func gcpTimestamp(value interface{}) interface{} {
+	t, ok := recordUnixTime(value)
+	if !ok {
+		return value
+	}
+
+	return record{
+		{key: "seconds", value: json.Number(strconv.FormatInt(t.Unix(), 10))},
+		{key: "nanos", value: json.Number(strconv.Itoa(t.Nanosecond()))},
+	}
+}
+
+// gcpTrace returns the resource name of the trace, which is prefixed by "projects/<GCPProjectID>/traces/".
+func gcpTrace(value interface{}) interface{} {
+	trace, ok := value.(string)
+	if !ok || GCPProjectID == "" || strings.HasPrefix(trace, "projects/") {
+		return value
+	}
+
+	return "projects/" + GCPProjectID + "/traces/" + trace
+}
+
+// gcpSourceLocation returns the source location of the caller and the func fields.
+func gcpSourceLocation(r record) record {
+	caller, ok := r.getString(zerolog.CallerFieldName)
+	if !ok {
+		return nil
+	}
+
+	location := record{}
+
+	if i := strings.LastIndexByte(caller, ':'); i >= 0 {
+		location = append(location, recordField{key: "file", value: caller[:i]}, recordField{key: "line", value: caller[i+1:]})
+	} else {
+		location = append(location, recordField{key: "file", value: caller})
+	}
+
+	if fn, ok := r.getString(CallerFuncFieldName); ok {
+		location = append(location, recordField{key: "function", value: fn})
+	}
+
+	return location
+}
+
+// gcpReportError marks out as ReportedErrorEvent if r has an error,
+// with the stack trace in the format of Go panics or the location reported by the caller.
+func gcpReportError(out record, r record) record {
+	errValue, ok := r.get(zerolog.ErrorFieldName)
+	if !ok || errValue == nil {
+		return out
+	}
+
+	message := fmt.Sprint(errValue)
+	stack, _ := r.get(zerolog.ErrorStackFieldName)
+
+	if errObject, ok := errValue.(record); ok {
+		if s, ok := errObject.getString("message"); ok {
+			message = s
+		}
+
+		if errStack, ok := errObject.get("stack"); ok {
+			stack = errStack
+		}
+	}
+
+	out = out.set("@type", gcpReportedErrorEvent)
+
+	if frames, ok := stack.([]interface{}); ok && len(frames) > 0 {
+		return out.set("stack_trace", gcpStackTrace(message, frames))
+	}
+
+	if location := gcpSourceLocation(r); location != nil {
+		return out.set("context", record{{key: "reportLocation", value: record{
+			{key: "filePath", value: location[0].value},
+			{key: "lineNumber", value: gcpLineNumber(location)},
+			{key: "functionName", value: gcpFunctionName(location)},
+		}}})
+	}
+
+	return out
+}
+
+// gcpStackTrace formats frames like the stack trace of Go panics, which is parsed by Error Reporting.
+func gcpStackTrace(message string, frames []interface{}) string {
+	return message + "\n\ngoroutine 1 [running]:\n" + recordStackTrace(frames)
+}
+
+// gcpLineNumber returns the line of the source location as a number.
+func gcpLineNumber(location record) interface{} {
+	if line, ok := location.getString("line"); ok {
+		if _, err := strconv.Atoi(line); err == nil {
+			return json.Number(line)
+		}
+	}
+
+	return json.Number("0")
+}
+
+// gcpFunctionName returns the function of the source location.
+func gcpFunctionName(location record) string {
+	fn, _ := location.getString("function")
+
+	return fn
+}
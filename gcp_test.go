@@ -0,0 +1,208 @@
+package logs_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	logs "github.com/rtkym/logs-go"
+	"github.com/stretchr/testify/assert"
+)
+
+// decodeLine decodes the JSON line written to buf.
+func decodeLine(t *testing.T, buf *bytes.Buffer) map[string]interface{} {
+	t.Helper()
+
+	res := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &res), buf.String())
+	buf.Reset()
+
+	return res
+}
+
+func TestGCPWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	newLogger := func(opts ...logs.OptionFunc) *logs.Logger {
+		return logs.NewWithOption(append([]logs.OptionFunc{
+			logs.OptionLevel("trace"),
+			func(opt *logs.Option) { opt.Writer = logs.ExpNewFormatWriter("gcp", buf) },
+		}, opts...)...)
+	}
+
+	t.Run("severity", func(t *testing.T) {
+		logger := newLogger(logs.OptionExitFunc(func(int) {}))
+
+		for _, tt := range []struct {
+			fn       func(string)
+			severity string
+		}{
+			{logger.Trace, "DEBUG"},
+			{logger.Debug, "DEBUG"},
+			{logger.Info, "INFO"},
+			{logger.Warn, "WARNING"},
+			{logger.Error, "ERROR"},
+			{logger.Fatal, "CRITICAL"},
+		} {
+			tt.fn("test msg")
+
+			res := decodeLine(t, buf)
+			assert.Equal(t, tt.severity, res["severity"])
+			assert.Equal(t, "test msg", res["message"])
+			assert.NotEmpty(t, res["timestamp"])
+			assert.NotContains(t, res, "level")
+			assert.NotContains(t, res, "time")
+		}
+
+		_, err := logs.ExpNewFormatWriter("gcp", buf).Write(logRecord("no level"))
+		assert.NoError(t, err)
+		assert.Equal(t, `{"severity":"DEFAULT","message":"no level"}`+"\n", buf.String())
+		buf.Reset()
+	})
+
+	t.Run("timestamp", func(t *testing.T) {
+		defer func(format string) { zerolog.TimeFieldFormat = format }(zerolog.TimeFieldFormat)
+
+		zerolog.TimeFieldFormat = zerolog.TimeFormatUnixMs
+
+		var record bytes.Buffer
+
+		logger := zerolog.New(&record)
+		logger.Info().Int64("time", 1660626347123).Send()
+
+		_, err := logs.ExpNewFormatWriter("gcp", buf).Write(record.Bytes())
+		assert.NoError(t, err)
+		assert.Equal(t, `{"severity":"INFO","timestamp":{"seconds":1660626347,"nanos":123000000}}`+"\n", buf.String())
+		buf.Reset()
+	})
+
+	t.Run("TraceContext", func(t *testing.T) {
+		defer func(id string) { logs.GCPProjectID = id }(logs.GCPProjectID)
+
+		logs.GCPProjectID = "my-project"
+		newLogger().Entry().TraceContext("0123456789abcdef0123456789abcdef", "0123456789abcdef", true).Info("test msg")
+
+		res := decodeLine(t, buf)
+		assert.Equal(t, "projects/my-project/traces/0123456789abcdef0123456789abcdef", res["logging.googleapis.com/trace"])
+		assert.Equal(t, "0123456789abcdef", res["logging.googleapis.com/spanId"])
+		assert.Equal(t, true, res["logging.googleapis.com/trace_sampled"])
+		assert.NotContains(t, res, "trace_id")
+	})
+
+	t.Run("sourceLocation", func(t *testing.T) {
+		newLogger(logs.OptionCallerFunc()).Info("test msg")
+
+		res := decodeLine(t, buf)
+		location, _ := res["logging.googleapis.com/sourceLocation"].(map[string]interface{})
+		assert.True(t, strings.HasSuffix(location["file"].(string), "gcp_test.go"), location)
+		assert.NotEmpty(t, location["line"])
+		assert.Equal(t, "github.com/rtkym/logs-go_test.TestGCPWriter.func5", location["function"])
+		assert.NotContains(t, res, "caller")
+		assert.NotContains(t, res, "func")
+	})
+
+	t.Run("httpRequest", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/path?q=1", nil)
+		req.Header.Set("User-Agent", "test-agent")
+
+		newLogger().Entry().HTTPRequest(logs.NewHTTPRequest(req, 200, 123, 1500*time.Millisecond)).Info("test msg")
+
+		res := decodeLine(t, buf)
+		assert.Equal(t, map[string]interface{}{
+			"requestMethod": "GET",
+			"requestUrl":    "/path?q=1",
+			"status":        float64(200),
+			"responseSize":  "123",
+			"userAgent":     "test-agent",
+			"remoteIp":      "192.0.2.1:1234",
+			"latency":       "1.5s",
+			"protocol":      "HTTP/1.1",
+		}, res["httpRequest"])
+
+		newLogger().Entry().HTTPRequest(nil).Info("test msg")
+		assert.Nil(t, decodeLine(t, buf)["httpRequest"])
+	})
+
+	t.Run("Error Reporting", func(t *testing.T) {
+		newLogger(logs.OptionCallerFunc()).E(errors.New("test error")).Error("test msg")
+
+		res := decodeLine(t, buf)
+		assert.Equal(t, "type.googleapis.com/google.devtools.clouderrorreporting.v1beta1.ReportedErrorEvent", res["@type"])
+		location := res["context"].(map[string]interface{})["reportLocation"].(map[string]interface{})
+		assert.True(t, strings.HasSuffix(location["filePath"].(string), "gcp_test.go"), location)
+		assert.Greater(t, location["lineNumber"], float64(0))
+		assert.Equal(t, "github.com/rtkym/logs-go_test.TestGCPWriter.func7", location["functionName"])
+
+		newLogger().E(NewStackError()).Error("test msg")
+
+		res = decodeLine(t, buf)
+		assert.Equal(t, "type.googleapis.com/google.devtools.clouderrorreporting.v1beta1.ReportedErrorEvent", res["@type"])
+		assert.True(t, strings.HasPrefix(res["stack_trace"].(string), "StackError\n\ngoroutine 1 [running]:\n"), res["stack_trace"])
+		assert.Contains(t, res["stack_trace"], "gcp_test.go:")
+
+		newLogger().E(errors.New("test error")).Warn("test msg")
+		assert.NotContains(t, decodeLine(t, buf), "@type")
+	})
+
+	t.Run("Error Reporting without caller", func(t *testing.T) {
+		newLogger().E(errors.New("test error")).Error("test msg")
+
+		res := decodeLine(t, buf)
+		assert.Equal(t, "type.googleapis.com/google.devtools.clouderrorreporting.v1beta1.ReportedErrorEvent", res["@type"])
+		location := res["context"].(map[string]interface{})["reportLocation"].(map[string]interface{})
+		assert.True(t, strings.HasSuffix(location["filePath"].(string), "gcp_test.go"), location)
+		assert.Equal(t, "github.com/rtkym/logs-go_test.TestGCPWriter.func8", location["functionName"])
+
+		logger := newLogger(logs.OptionAsync(logs.AsyncConfig{}))
+		logger.E(errors.New("test error")).Error("test msg")
+		assert.NoError(t, logger.Close())
+
+		res = decodeLine(t, buf)
+		assert.Contains(t, res, "context")
+
+		newLogger().Error("test msg")
+		res = decodeLine(t, buf)
+		assert.NotContains(t, res, "@type")
+		assert.NotContains(t, res, "logging.googleapis.com/sourceLocation")
+
+		newLogger().E(errors.New("test error")).Warn("test msg")
+		assert.NotContains(t, decodeLine(t, buf), "logging.googleapis.com/sourceLocation")
+
+		newLogger().EK("cause", errors.New("test error")).Error("test msg")
+		res = decodeLine(t, buf)
+		assert.NotContains(t, res, "@type")
+		assert.NotContains(t, res, "logging.googleapis.com/sourceLocation")
+
+		newLogger().E(nil).Error("test msg")
+		res = decodeLine(t, buf)
+		assert.NotContains(t, res, "@type")
+		assert.NotContains(t, res, "logging.googleapis.com/sourceLocation")
+	})
+
+	t.Run("OptionFormat", func(t *testing.T) {
+		opt := &logs.Option{}
+		logs.OptionFormat("gcp")(opt)
+		assert.IsType(t, logs.ExpNewFormatWriter("gcp", nil), opt.Writer)
+	})
+
+	t.Run("OptionWriter,LOG_FORMAT", func(t *testing.T) {
+		outputs := formatOutputs(t, "gcp", func(logger *logs.Logger) { logger.E(errors.New("test error")).Error("test msg") })
+
+		for name, output := range outputs {
+			res := map[string]interface{}{}
+			assert.NoError(t, json.Unmarshal([]byte(output), &res), name)
+			assert.Equal(t, "ERROR", res["severity"], name)
+			assert.Equal(t, "test msg", res["message"], name)
+			assert.Equal(t, "type.googleapis.com/google.devtools.clouderrorreporting.v1beta1.ReportedErrorEvent", res["@type"], name)
+
+			location := res["context"].(map[string]interface{})["reportLocation"].(map[string]interface{})
+			assert.True(t, strings.HasSuffix(location["filePath"].(string), "gcp_test.go"), location)
+		}
+
+		assert.Len(t, outputs, 2)
+	})
+}
@@ -23,22 +23,67 @@ func InitGlobalLogger() {
 func Entry() *LogEntry { return gLogger.Entry() }
 
 // Trace outputs messages at trace level.
-func Trace(msg string) { gLogger.Entry().Trace(msg) }
+func Trace(msg string) { gLogger.entry(1).Trace(msg) }
 
 // Debug outputs messages at debug level.
-func Debug(msg string) { gLogger.Entry().Debug(msg) }
+func Debug(msg string) { gLogger.entry(1).Debug(msg) }
 
 // Info outputs messages at info level.
-func Info(msg string) { gLogger.Entry().Info(msg) }
+func Info(msg string) { gLogger.entry(1).Info(msg) }
 
 // Warn outputs messages at warn level.
-func Warn(msg string) { gLogger.Entry().Warn(msg) }
+func Warn(msg string) { gLogger.entry(1).Warn(msg) }
 
 // Error outputs messages at error level.
-func Error(msg string) { gLogger.Entry().Error(msg) }
+func Error(msg string) { gLogger.entry(1).Error(msg) }
 
-// Fatal outputs messages at fatal level.
-func Fatal(msg string) { gLogger.Entry().Fatal(msg) }
+// Fatal outputs messages at fatal level, and then exits with status 1.
+func Fatal(msg string) { gLogger.entry(1).Fatal(msg) }
+
+// Panic outputs messages at panic level, and then panics with the message.
+func Panic(msg string) { gLogger.entry(1).Panic(msg) }
+
+// Tracef outputs messages formatted by fmt.Sprintf at trace level.
+func Tracef(format string, args ...interface{}) { gLogger.entry(1).Tracef(format, args...) }
+
+// Debugf outputs messages formatted by fmt.Sprintf at debug level.
+func Debugf(format string, args ...interface{}) { gLogger.entry(1).Debugf(format, args...) }
+
+// Infof outputs messages formatted by fmt.Sprintf at info level.
+func Infof(format string, args ...interface{}) { gLogger.entry(1).Infof(format, args...) }
+
+// Warnf outputs messages formatted by fmt.Sprintf at warn level.
+func Warnf(format string, args ...interface{}) { gLogger.entry(1).Warnf(format, args...) }
+
+// Errorf outputs messages formatted by fmt.Sprintf at error level.
+func Errorf(format string, args ...interface{}) { gLogger.entry(1).Errorf(format, args...) }
+
+// Fatalf outputs messages formatted by fmt.Sprintf at fatal level.
+func Fatalf(format string, args ...interface{}) { gLogger.entry(1).Fatalf(format, args...) }
+
+// Panicf outputs messages formatted by fmt.Sprintf at panic level, and then panics with the message.
+func Panicf(format string, args ...interface{}) { gLogger.entry(1).Panicf(format, args...) }
+
+// Tracew outputs messages at trace level with key and value pairs.
+func Tracew(msg string, keyvals ...interface{}) { gLogger.entry(1).Tracew(msg, keyvals...) }
+
+// Debugw outputs messages at debug level with key and value pairs.
+func Debugw(msg string, keyvals ...interface{}) { gLogger.entry(1).Debugw(msg, keyvals...) }
+
+// Infow outputs messages at info level with key and value pairs.
+func Infow(msg string, keyvals ...interface{}) { gLogger.entry(1).Infow(msg, keyvals...) }
+
+// Warnw outputs messages at warn level with key and value pairs.
+func Warnw(msg string, keyvals ...interface{}) { gLogger.entry(1).Warnw(msg, keyvals...) }
+
+// Errorw outputs messages at error level with key and value pairs.
+func Errorw(msg string, keyvals ...interface{}) { gLogger.entry(1).Errorw(msg, keyvals...) }
+
+// Fatalw outputs messages at fatal level with key and value pairs.
+func Fatalw(msg string, keyvals ...interface{}) { gLogger.entry(1).Fatalw(msg, keyvals...) }
+
+// Panicw outputs messages at panic level with key and value pairs, and then panics with the message.
+func Panicw(msg string, keyvals ...interface{}) { gLogger.entry(1).Panicw(msg, keyvals...) }
 
 // V adds key and value attribute to log message.
 func V(key string, value interface{}) *LogEntry {
@@ -50,6 +95,31 @@ func E(err error) *LogEntry {
 	return gLogger.Entry().E(err)
 }
 
+// EK adds error attribute with key to log message.
+func EK(key string, err error) *LogEntry {
+	return gLogger.Entry().EK(key, err)
+}
+
+// AddCallerSkip returns a child of the global logger which skips n more stack frames to find the caller.
+func AddCallerSkip(n int) *Logger {
+	return gLogger.AddCallerSkip(n)
+}
+
+// Named returns a child of the global logger for a subsystem.
+func Named(name string) *Logger {
+	return gLogger.Named(name)
+}
+
+// GetLevel returns the current level of the global logger.
+func GetLevel() Level {
+	return gLogger.Level()
+}
+
+// SetLevel changes the level of the global logger.
+func SetLevel(level Level) {
+	gLogger.SetLevel(level)
+}
+
 // Set saves key and value to logger. The key and value are output permanently
 func Set(key string, value interface{}) {
 	gLogger.Set(key, value)
@@ -60,9 +130,14 @@ func SetWithZC(fn func(zc ZC) ZC) {
 	gLogger.SetWithZC(fn)
 }
 
-// With gets zerolog.Context
-func With() zerolog.Context {
-	return gLogger.With()
+// With returns a child of the global logger with key and value pairs added to it.
+func With(keyvals ...interface{}) *Logger {
+	return gLogger.With(keyvals...)
+}
+
+// ZeroContext gets zerolog.Context
+func ZeroContext() zerolog.Context {
+	return gLogger.ZeroContext()
 }
 
 // ZC is alias for zerolog.Context.
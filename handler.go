@@ -0,0 +1,184 @@
+package logs
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalidTTL is returned when the ttl of a level change is not a positive duration.
+var ErrInvalidTTL = errors.New("invalid ttl")
+
+// LevelHandler returns an http.Handler that reads and changes the level of logger.
+//
+// GET responds with the current level:
+//
+//	{"level":"info"}
+//
+// PUT and POST change the level. The body is a JSON object, or form values with the same names.
+// If ttl is given, the level reverts after the duration:
+//
+//	{"level":"debug","ttl":"5m"}
+//
+// The level is changed through the AtomicLevel of logger, so every logger sharing it is affected.
+func LevelHandler(logger *Logger) http.Handler {
+	return &levelHandler{logger: logger}
+}
+
+type levelHandler struct {
+	logger *Logger
+
+	mu       sync.Mutex
+	timer    *time.Timer
+	revertTo Level
+	expires  time.Time
+
+	// generation is incremented by every change, so that a timer which has fired
+	// before it is stopped does not revert a later change.
+	generation uint64
+}
+
+type levelRequest struct {
+	Level string `json:"level"`
+	TTL   string `json:"ttl,omitempty"`
+}
+
+type levelResponse struct {
+	Level     string     `json:"level"`
+	RevertTo  string     `json:"revert_to,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+func (x *levelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		x.writeResponse(w, http.StatusOK, nil)
+	case http.MethodPut, http.MethodPost:
+		if err := x.change(r); err != nil {
+			x.writeResponse(w, http.StatusBadRequest, err)
+
+			return
+		}
+
+		x.writeResponse(w, http.StatusOK, nil)
+	default:
+		w.Header().Set("Allow", "GET, PUT, POST")
+		x.writeResponse(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s is not allowed", r.Method)) // nolint:goerr113
+	}
+}
+
+// change applies the level change requested by r.
+func (x *levelHandler) change(r *http.Request) error {
+	req, err := decodeLevelRequest(r)
+	if err != nil {
+		return err
+	}
+
+	level, err := ParseLevel(req.Level)
+	if err != nil {
+		return err
+	}
+
+	var ttl time.Duration
+
+	if req.TTL != "" {
+		if ttl, err = time.ParseDuration(req.TTL); err != nil || ttl <= 0 {
+			return fmt.Errorf("%w: %q", ErrInvalidTTL, req.TTL)
+		}
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	current := x.logger.Level()
+	previous := current
+
+	if x.timer != nil {
+		// a pending revert keeps its original level, so that nested changes still end at the baseline.
+		x.timer.Stop()
+		x.timer = nil
+		previous = x.revertTo
+	}
+
+	x.generation++
+	x.logger.SetLevel(level)
+
+	// The keys do not collide with the level of the message itself.
+	entry := x.logger.V("new_level", level.String()).V("previous_level", current.String())
+
+	if ttl > 0 {
+		generation := x.generation
+		x.revertTo = previous
+		x.expires = time.Now().Add(ttl)
+		x.timer = time.AfterFunc(ttl, func() { x.revert(generation) })
+
+		entry.V("revert_to", previous.String()).V("ttl", ttl.String())
+	}
+
+	entry.Warn("log level changed")
+
+	return nil
+}
+
+// revert restores the level saved by the change of generation with ttl,
+// unless the level has been changed again since.
+func (x *levelHandler) revert(generation uint64) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if x.timer == nil || x.generation != generation {
+		return
+	}
+
+	x.timer = nil
+	current := x.logger.Level()
+	x.logger.SetLevel(x.revertTo)
+	x.logger.V("new_level", x.revertTo.String()).V("previous_level", current.String()).Warn("log level reverted")
+}
+
+func (x *levelHandler) writeResponse(w http.ResponseWriter, status int, err error) {
+	x.mu.Lock()
+	res := levelResponse{Level: x.logger.Level().String()}
+
+	if x.timer != nil {
+		expires := x.expires
+		res.RevertTo = x.revertTo.String()
+		res.ExpiresAt = &expires
+	}
+	x.mu.Unlock()
+
+	if err != nil {
+		res.Error = err.Error()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+// decodeLevelRequest reads a levelRequest from form values or a JSON body.
+func decodeLevelRequest(r *http.Request) (*levelRequest, error) {
+	req := &levelRequest{}
+
+	if r.ContentLength == 0 || strings.HasPrefix(r.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		if err := r.ParseForm(); err != nil {
+			return nil, fmt.Errorf("invalid request body: %w", err)
+		}
+
+		req.Level = r.Form.Get("level")
+		req.TTL = r.Form.Get("ttl")
+
+		return req, nil
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		return nil, fmt.Errorf("invalid request body: %w", err)
+	}
+
+	return req, nil
+}
@@ -0,0 +1,138 @@
+package logs_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	logs "github.com/rtkym/logs-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func serveLevel(t *testing.T, handler http.Handler, method, contentType, body string) (int, map[string]interface{}) {
+	t.Helper()
+
+	req := httptest.NewRequest(method, "/log/level", strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	res := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	return rec.Code, res
+}
+
+func TestLevelHandler(t *testing.T) {
+	t.Run("GET", func(t *testing.T) {
+		logger := logs.NewWithOption(logs.OptionLevel("warn"), func(opt *logs.Option) { opt.Writer = jsonWriter(&bytes.Buffer{}) })
+
+		code, res := serveLevel(t, logs.LevelHandler(logger), http.MethodGet, "", "")
+
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, map[string]interface{}{"level": "warn"}, res)
+	})
+
+	t.Run("PUT", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger := logs.NewWithOption(func(opt *logs.Option) { opt.Writer = jsonWriter(buf) })
+		handler := logs.LevelHandler(logger)
+
+		code, res := serveLevel(t, handler, http.MethodPut, "application/json", `{"level":"debug"}`)
+
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, map[string]interface{}{"level": "debug"}, res)
+		assert.Equal(t, logs.DebugLevel, logger.Level())
+
+		line := decodeLine(t, buf)
+		assert.Equal(t, "warn", line["level"])
+		assert.Equal(t, "debug", line["new_level"])
+		assert.Equal(t, "info", line["previous_level"])
+		assert.Equal(t, "log level changed", line["message"])
+
+		code, res = serveLevel(t, handler, http.MethodPost, "application/x-www-form-urlencoded", "level=error")
+
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "error", res["level"])
+		assert.Equal(t, logs.ErrorLevel, logger.Level())
+	})
+
+	t.Run("TTL", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger := logs.NewWithOption(func(opt *logs.Option) { opt.Writer = jsonWriter(buf) })
+		handler := logs.LevelHandler(logger)
+
+		code, res := serveLevel(t, handler, http.MethodPut, "", `{"level":"trace","ttl":"50ms"}`)
+
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "trace", res["level"])
+		assert.Equal(t, "info", res["revert_to"])
+		assert.NotEmpty(t, res["expires_at"])
+
+		code, res = serveLevel(t, handler, http.MethodPut, "", `{"level":"debug","ttl":"50ms"}`)
+
+		assert.Equal(t, http.StatusOK, code)
+		assert.Equal(t, "debug", res["level"])
+		assert.Equal(t, "info", res["revert_to"])
+
+		assert.Eventually(t, func() bool { return logger.Level() == logs.InfoLevel }, time.Second, 10*time.Millisecond)
+
+		_, res = serveLevel(t, handler, http.MethodGet, "", "")
+
+		assert.Equal(t, map[string]interface{}{"level": "info"}, res)
+	})
+
+	t.Run("stale revert", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger := logs.NewWithOption(func(opt *logs.Option) { opt.Writer = jsonWriter(buf) })
+		handler := logs.LevelHandler(logger)
+
+		serveLevel(t, handler, http.MethodPut, "", `{"level":"trace","ttl":"1h"}`)
+		generation := logs.ExpLevelGeneration(handler)
+		serveLevel(t, handler, http.MethodPut, "", `{"level":"debug","ttl":"1h"}`)
+
+		// The timer of the first change has fired before the second change stopped it.
+		logs.ExpRevertLevel(handler, generation)
+
+		assert.Equal(t, logs.DebugLevel, logger.Level())
+
+		buf.Reset()
+		logs.ExpRevertLevel(handler, logs.ExpLevelGeneration(handler))
+
+		assert.Equal(t, logs.InfoLevel, logger.Level())
+
+		line := decodeLine(t, buf)
+		assert.Equal(t, "warn", line["level"])
+		assert.Equal(t, "info", line["new_level"])
+		assert.Equal(t, "debug", line["previous_level"])
+		assert.Equal(t, "log level reverted", line["message"])
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		logger := logs.NewWithOption(func(opt *logs.Option) { opt.Writer = jsonWriter(&bytes.Buffer{}) })
+		handler := logs.LevelHandler(logger)
+
+		code, res := serveLevel(t, handler, http.MethodPut, "application/json", `{"level":"verbose"}`)
+
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Contains(t, res["error"], "invalid log level")
+
+		code, res = serveLevel(t, handler, http.MethodPut, "application/json", `{"level":"debug","ttl":"-1s"}`)
+
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Contains(t, res["error"], "invalid ttl")
+
+		code, _ = serveLevel(t, handler, http.MethodDelete, "", "")
+
+		assert.Equal(t, http.StatusMethodNotAllowed, code)
+		assert.Equal(t, logs.InfoLevel, logger.Level())
+	})
+}
@@ -0,0 +1,10 @@
+//go:build !binary_log
+// +build !binary_log
+
+package logs
+
+// binaryLog is set in binary_log builds, where zerolog writes CBOR instead of JSON.
+const binaryLog = false
+
+// scopeMarker is the bytes of the scope field in JSON lines.
+var scopeMarker = []byte(`,"` + scopeFieldName + `":true`) // nolint:gochecknoglobals
@@ -0,0 +1,98 @@
+package logs
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/rs/zerolog"
+)
+
+// Level is alias for zerolog.Level.
+type Level = zerolog.Level
+
+// Log levels.
+const (
+	TraceLevel = zerolog.TraceLevel
+	DebugLevel = zerolog.DebugLevel
+	InfoLevel  = zerolog.InfoLevel
+	WarnLevel  = zerolog.WarnLevel
+	ErrorLevel = zerolog.ErrorLevel
+	FatalLevel = zerolog.FatalLevel
+	PanicLevel = zerolog.PanicLevel
+	Disabled   = zerolog.Disabled
+)
+
+// ErrInvalidLevel is returned when a string cannot be parsed as a log level.
+var ErrInvalidLevel = errors.New("invalid log level")
+
+// ParseLevel converts a level string into a Level.
+//
+// Names are case-insensitive. Besides the canonical names (trace, debug, info,
+// warn, error, fatal, panic, disabled), the aliases warning, err, crit,
+// critical, off and none are accepted, as well as zerolog's numeric levels
+// (-1 for trace to 5 for panic, 7 for disabled).
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "trace":
+		return TraceLevel, nil
+	case "debug":
+		return DebugLevel, nil
+	case "info":
+		return InfoLevel, nil
+	case "warn", "warning":
+		return WarnLevel, nil
+	case "error", "err":
+		return ErrorLevel, nil
+	case "fatal", "crit", "critical":
+		return FatalLevel, nil
+	case "panic":
+		return PanicLevel, nil
+	case "disabled", "off", "none":
+		return Disabled, nil
+	}
+
+	if i, err := strconv.Atoi(strings.TrimSpace(s)); err == nil {
+		if (int(TraceLevel) <= i && i <= int(PanicLevel)) || i == int(Disabled) {
+			return Level(i), nil
+		}
+	}
+
+	return InfoLevel, fmt.Errorf("%w: %q", ErrInvalidLevel, s)
+}
+
+// AtomicLevel is a log level that can be changed while loggers sharing it are in use.
+// It is safe for concurrent use.
+type AtomicLevel struct {
+	v int32
+}
+
+// NewAtomicLevel returns a new AtomicLevel set to level.
+func NewAtomicLevel(level Level) *AtomicLevel {
+	x := &AtomicLevel{}
+	x.SetLevel(level)
+
+	return x
+}
+
+// Level returns the current level.
+func (x *AtomicLevel) Level() Level {
+	return Level(atomic.LoadInt32(&x.v))
+}
+
+// SetLevel changes the level. It takes effect immediately on every logger sharing x.
+func (x *AtomicLevel) SetLevel(level Level) {
+	atomic.StoreInt32(&x.v, int32(level))
+}
+
+// Enabled reports whether messages at level are output.
+func (x *AtomicLevel) Enabled(level Level) bool {
+	return level >= x.Level()
+}
+
+// String returns the name of the current level.
+func (x *AtomicLevel) String() string {
+	return x.Level().String()
+}
@@ -0,0 +1,140 @@
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/rs/zerolog"
+)
+
+// OptionLogfmtWriter returns an OptionFunc for configuring logfmt format.
+func OptionLogfmtWriter() OptionFunc {
+	return func(opt *Option) {
+		opt.setFormat(func(w io.Writer) io.Writer { return newLogfmtWriter(w) })
+	}
+}
+
+// newLogfmtWriter returns a writer rendering zerolog's JSON lines as logfmt "key=value" lines to out.
+//
+// The time, level and message are output first. Nested objects and arrays, such as errors added by E,
+// are flattened with dotted keys, such as "error.message" and "stack.0.func".
+func newLogfmtWriter(out io.Writer) *reshapeWriter {
+	return &reshapeWriter{out: out, reshape: logfmtRecord, encode: appendLogfmt}
+}
+
+// logfmtRecord moves the time, the level and the message of r to the front.
+func logfmtRecord(r record) record {
+	first := []string{zerolog.TimestampFieldName, zerolog.LevelFieldName, zerolog.MessageFieldName}
+	out := make(record, 0, len(r))
+
+	for _, key := range first {
+		if value, ok := r.get(key); ok {
+			out = append(out, recordField{key: key, value: value})
+		}
+	}
+
+	for _, f := range r {
+		if !containsString(first, f.key) {
+			out = append(out, f)
+		}
+	}
+
+	return out
+}
+
+// appendLogfmt encodes r in logfmt to buf.
+func appendLogfmt(buf *bytes.Buffer, r record) {
+	for _, f := range r {
+		writeLogfmt(buf, f.key, f.value)
+	}
+}
+
+// writeLogfmt writes key=value, flattening nested values with dotted keys.
+func writeLogfmt(buf *bytes.Buffer, key string, value interface{}) {
+	switch v := value.(type) {
+	case record:
+		if len(v) == 0 {
+			writeLogfmtPair(buf, key, "{}")
+		}
+
+		for _, f := range v {
+			writeLogfmt(buf, key+"."+f.key, f.value)
+		}
+
+		return
+	case []interface{}:
+		if len(v) == 0 {
+			writeLogfmtPair(buf, key, "[]")
+		}
+
+		for i, elem := range v {
+			writeLogfmt(buf, key+"."+strconv.Itoa(i), elem)
+		}
+
+		return
+	case string:
+		writeLogfmtPair(buf, key, logfmtQuote(v))
+	case json.Number:
+		writeLogfmtPair(buf, key, v.String())
+	case bool:
+		writeLogfmtPair(buf, key, strconv.FormatBool(v))
+	case nil:
+		writeLogfmtPair(buf, key, "null")
+	}
+}
+
+// writeLogfmtPair writes key=value separated by a space from the previous pair.
+func writeLogfmtPair(buf *bytes.Buffer, key, value string) {
+	if buf.Len() > 0 {
+		buf.WriteByte(' ')
+	}
+
+	buf.WriteString(logfmtKey(key))
+	buf.WriteByte('=')
+	buf.WriteString(value)
+}
+
+// logfmtKey replaces the characters which cannot be in a key with '_'.
+func logfmtKey(key string) string {
+	if key == "" {
+		return badKey
+	}
+
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' || !unicode.IsPrint(r) {
+			return '_'
+		}
+
+		return r
+	}, key)
+}
+
+// logfmtQuote quotes s if it is empty or has spaces, '=', '"' or unprintable characters.
+func logfmtQuote(s string) string {
+	if s == "" {
+		return `""`
+	}
+
+	for _, r := range s {
+		if r <= ' ' || r == '=' || r == '"' || r == '\\' || !unicode.IsPrint(r) {
+			return strconv.Quote(s)
+		}
+	}
+
+	return s
+}
+
+// containsString reports whether s is in a.
+func containsString(a []string, s string) bool {
+	for _, elem := range a {
+		if elem == s {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,93 @@
+package logs_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	logs "github.com/rtkym/logs-go"
+	"github.com/stretchr/testify/assert"
+)
+
+// logfmtLine returns the line without the leading time.
+func logfmtLine(t *testing.T, buf *bytes.Buffer) string {
+	t.Helper()
+
+	line := strings.TrimSuffix(buf.String(), "\n")
+	buf.Reset()
+
+	assert.True(t, strings.HasPrefix(line, "time="), line)
+
+	if i := strings.IndexByte(line, ' '); i >= 0 {
+		return line[i+1:]
+	}
+
+	return line
+}
+
+func TestLogfmtWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := logs.NewWithOption(func(opt *logs.Option) { opt.Writer = logs.ExpNewFormatWriter("logfmt", buf) })
+
+	t.Run("quoting", func(t *testing.T) {
+		logger.V("str", "plain").
+			V("space", "a b").
+			V("quote", `say "hi"`).
+			V("eq", "a=b").
+			V("newline", "a\nb").
+			V("empty", "").
+			V("int", 1).
+			V("float", 1.5).
+			V("bool", true).
+			V("nil", nil).
+			V("bad key", "v").
+			Info("test msg")
+
+		assert.Equal(t,
+			`level=info message="test msg" str=plain space="a b" quote="say \"hi\"" eq="a=b" newline="a\nb" `+
+				`empty="" int=1 float=1.5 bool=true nil=null bad_key=v`,
+			logfmtLine(t, buf))
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		logger.V("map", map[string]interface{}{"a": 1, "b": map[string]string{"c": "d"}}).
+			V("slice", []string{"x", "y"}).
+			V("emptyMap", map[string]int{}).
+			V("emptySlice", []int{}).
+			Info("test msg")
+
+		assert.Equal(t,
+			`level=info message="test msg" map.a=1 map.b.c=d slice.0=x slice.1=y emptyMap={} emptySlice=[]`,
+			logfmtLine(t, buf))
+	})
+
+	t.Run("E", func(t *testing.T) {
+		err := fmt.Errorf("wrapped: %w", errors.New("test error"))
+		logger.E(err).Error("test msg")
+
+		assert.Equal(t,
+			`level=error message="test msg" error.message="wrapped: test error" error.type=*fmt.wrapError `+
+				`error.causes.0.message="test error" error.causes.0.type=*errors.errorString`,
+			logfmtLine(t, buf))
+	})
+
+	t.Run("not JSON", func(t *testing.T) {
+		_, err := logs.ExpNewFormatWriter("logfmt", buf).Write([]byte("plain text\n"))
+		assert.NoError(t, err)
+		assert.Equal(t, "plain text\n", buf.String())
+		buf.Reset()
+	})
+
+	t.Run("OptionWriter,LOG_FORMAT", func(t *testing.T) {
+		outputs := formatOutputs(t, "logfmt", func(logger *logs.Logger) { logger.V("n", 1).Info("test msg") })
+
+		for name, output := range outputs {
+			assert.Regexp(t, `^time=\S+ level=info message="test msg" n=1\n$`, output, name)
+		}
+
+		assert.Len(t, outputs, 2)
+		assert.Contains(t, logs.Formats(), "logfmt")
+	})
+}
@@ -1,39 +1,132 @@
 package logs
 
 import (
+	"strconv"
+	"strings"
+	"sync"
+
 	"github.com/rs/zerolog"
 )
 
 // Logger provides basic logging functionality.
+//
+// A Logger is safe for concurrent use. Set and SetWithZC update the logger in place and
+// affect only later log calls on it, while With, WithZC and Named return children
+// without changing the parent.
 type Logger struct {
+	mu         sync.RWMutex
 	zeroLogger zerolog.Logger
+	level      *AtomicLevel
+	name       string
+	caller     callerConfig
+	core       *loggerCore
+
+	stacktraceLevel Level
+
+	// components holds the levels of named loggers. It is shared with children and never modified.
+	components map[string]*AtomicLevel
 }
 
+// LoggerFieldName is the field name used for the name of named loggers.
+var LoggerFieldName = "logger" // nolint:gochecknoglobals
+
 // Entry returns a new LogEntry
 func (x *Logger) Entry() *LogEntry {
-	return &LogEntry{
-		logger: x,
-		values: make(map[string]interface{}),
+	return newEntry(x)
+}
+
+// entry returns a new LogEntry for level methods wrapped by skip frames.
+func (x *Logger) entry(skip int) *LogEntry {
+	entry := newEntry(x)
+	entry.skip = skip
+
+	return entry
+}
+
+// newEvent starts a zerolog event at level. It returns nil, on which every zerolog call is a no-op, if level is disabled.
+// Fatal and Panic still exit and panic in that case, as zerolog does.
+func (x *Logger) newEvent(level zerolog.Level) *zerolog.Event {
+	if !x.level.Enabled(level) {
+		return nil
 	}
+
+	zeroLogger := x.zero()
+
+	ev := zeroLogger.WithLevel(level)
+
+	if x.core.scoped {
+		ev.Bool(scopeFieldName, true)
+	}
+
+	if x.name != "" {
+		ev.Str(LoggerFieldName, x.name)
+	}
+
+	return ev
 }
 
 // Trace outputs messages at trace level.
-func (x *Logger) Trace(msg string) { x.Entry().Trace(msg) }
+func (x *Logger) Trace(msg string) { x.entry(1).Trace(msg) }
 
 // Debug outputs messages at debug level.
-func (x *Logger) Debug(msg string) { x.Entry().Debug(msg) }
+func (x *Logger) Debug(msg string) { x.entry(1).Debug(msg) }
 
 // Info outputs messages at info level.
-func (x *Logger) Info(msg string) { x.Entry().Info(msg) }
+func (x *Logger) Info(msg string) { x.entry(1).Info(msg) }
 
 // Warn outputs messages at warn level.
-func (x *Logger) Warn(msg string) { x.Entry().Warn(msg) }
+func (x *Logger) Warn(msg string) { x.entry(1).Warn(msg) }
 
 // Error outputs messages at error level.
-func (x *Logger) Error(msg string) { x.Entry().Error(msg) }
+func (x *Logger) Error(msg string) { x.entry(1).Error(msg) }
+
+// Fatal outputs messages at fatal level, and then exits with status 1.
+func (x *Logger) Fatal(msg string) { x.entry(1).Fatal(msg) }
+
+// Panic outputs messages at panic level, and then panics with the message.
+func (x *Logger) Panic(msg string) { x.entry(1).Panic(msg) }
+
+// Tracef outputs messages formatted by fmt.Sprintf at trace level.
+func (x *Logger) Tracef(format string, args ...interface{}) { x.entry(1).Tracef(format, args...) }
 
-// Fatal outputs messages at fatal level.
-func (x *Logger) Fatal(msg string) { x.Entry().Fatal(msg) }
+// Debugf outputs messages formatted by fmt.Sprintf at debug level.
+func (x *Logger) Debugf(format string, args ...interface{}) { x.entry(1).Debugf(format, args...) }
+
+// Infof outputs messages formatted by fmt.Sprintf at info level.
+func (x *Logger) Infof(format string, args ...interface{}) { x.entry(1).Infof(format, args...) }
+
+// Warnf outputs messages formatted by fmt.Sprintf at warn level.
+func (x *Logger) Warnf(format string, args ...interface{}) { x.entry(1).Warnf(format, args...) }
+
+// Errorf outputs messages formatted by fmt.Sprintf at error level.
+func (x *Logger) Errorf(format string, args ...interface{}) { x.entry(1).Errorf(format, args...) }
+
+// Fatalf outputs messages formatted by fmt.Sprintf at fatal level.
+func (x *Logger) Fatalf(format string, args ...interface{}) { x.entry(1).Fatalf(format, args...) }
+
+// Panicf outputs messages formatted by fmt.Sprintf at panic level, and then panics with the message.
+func (x *Logger) Panicf(format string, args ...interface{}) { x.entry(1).Panicf(format, args...) }
+
+// Tracew outputs messages at trace level with key and value pairs.
+func (x *Logger) Tracew(msg string, keyvals ...interface{}) { x.entry(1).Tracew(msg, keyvals...) }
+
+// Debugw outputs messages at debug level with key and value pairs.
+func (x *Logger) Debugw(msg string, keyvals ...interface{}) { x.entry(1).Debugw(msg, keyvals...) }
+
+// Infow outputs messages at info level with key and value pairs.
+func (x *Logger) Infow(msg string, keyvals ...interface{}) { x.entry(1).Infow(msg, keyvals...) }
+
+// Warnw outputs messages at warn level with key and value pairs.
+func (x *Logger) Warnw(msg string, keyvals ...interface{}) { x.entry(1).Warnw(msg, keyvals...) }
+
+// Errorw outputs messages at error level with key and value pairs.
+func (x *Logger) Errorw(msg string, keyvals ...interface{}) { x.entry(1).Errorw(msg, keyvals...) }
+
+// Fatalw outputs messages at fatal level with key and value pairs.
+func (x *Logger) Fatalw(msg string, keyvals ...interface{}) { x.entry(1).Fatalw(msg, keyvals...) }
+
+// Panicw outputs messages at panic level with key and value pairs, and then panics with the message.
+func (x *Logger) Panicw(msg string, keyvals ...interface{}) { x.entry(1).Panicw(msg, keyvals...) }
 
 // V adds key and value attribute to log message.
 func (x *Logger) V(key string, value interface{}) *LogEntry {
@@ -45,17 +138,154 @@ func (x *Logger) E(err error) *LogEntry {
 	return x.Entry().E(err)
 }
 
+// EK adds error attribute with key to log message.
+func (x *Logger) EK(key string, err error) *LogEntry {
+	return x.Entry().EK(key, err)
+}
+
 // Set saves key and value attribute to logger. The attribute are output permanently.
+// It is safe to call while other goroutines are logging, and does not affect children created before.
 func (x *Logger) Set(key string, value interface{}) {
 	x.SetWithZC(func(zc ZC) ZC { return zc.Interface(key, value) })
 }
 
 // SetWithZC saves key and value to logger. The key and value are output permanently
+// It is safe to call while other goroutines are logging, and does not affect children created before.
 func (x *Logger) SetWithZC(fn func(zc ZC) ZC) {
-	x.zeroLogger = fn(x.With()).Logger()
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	x.zeroLogger = fn(x.zeroLogger.With()).Logger()
+}
+
+// With returns a child logger with key and value pairs added to it. The parent is not changed.
+// The child inherits the level, writer and attributes of the parent.
+//
+// Keys must be strings. A key which is not a string, or a key without value, is output with the key "!BADKEY",
+// numbered as "!BADKEY1", "!BADKEY2" and so on if there are more.
+func (x *Logger) With(keyvals ...interface{}) *Logger {
+	return x.WithZC(func(zc ZC) ZC {
+		forEachKeyval(keyvals, 0, func(key string, value interface{}) { zc = zc.Interface(key, value) })
+
+		return zc
+	})
+}
+
+// WithZC returns a child logger with attributes added by fn. The parent is not changed.
+func (x *Logger) WithZC(fn func(zc ZC) ZC) *Logger {
+	child := x.clone()
+	child.zeroLogger = fn(child.zeroLogger.With()).Logger()
+
+	return child
+}
+
+// ZeroContext gets zerolog.Context
+func (x *Logger) ZeroContext() zerolog.Context {
+	zeroLogger := x.zero()
+
+	return zeroLogger.With()
+}
+
+// zero returns a snapshot of the zerolog logger, which is safe to use while x is updated by Set.
+func (x *Logger) zero() zerolog.Logger {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+
+	return x.zeroLogger
+}
+
+// clone returns a copy of x sharing its level, writer and attributes.
+func (x *Logger) clone() *Logger {
+	return &Logger{
+		zeroLogger: x.zero(),
+		level:      x.level,
+		name:       x.name,
+		caller:     x.caller,
+		core:       x.core,
+		components: x.components,
+
+		stacktraceLevel: x.stacktraceLevel,
+	}
+}
+
+// Level returns the current level of the logger.
+func (x *Logger) Level() Level {
+	return x.level.Level()
+}
+
+// SetLevel changes the level of the logger and every logger sharing its AtomicLevel.
+// It is safe to call while other goroutines are logging.
+func (x *Logger) SetLevel(level Level) {
+	x.level.SetLevel(level)
+}
+
+// AtomicLevel returns the level handle shared by the logger.
+func (x *Logger) AtomicLevel() *AtomicLevel {
+	return x.level
+}
+
+// Named returns a child logger for a subsystem. The name is appended to the name of x with a dot,
+// and is output as the "logger" field.
+//
+// The child uses the level of the longest dotted prefix of its name found in Option.ComponentLevels,
+// such as "db" for "db.pool", and shares the level of x otherwise.
+func (x *Logger) Named(name string) *Logger {
+	if x.name != "" {
+		name = x.name + "." + name
+	}
+
+	child := x.clone()
+	child.name = name
+
+	for prefix := name; len(prefix) > len(x.name); {
+		if level, ok := x.components[prefix]; ok {
+			child.level = level
+
+			break
+		}
+
+		i := strings.LastIndexByte(prefix, '.')
+		if i < 0 {
+			break
+		}
+
+		prefix = prefix[:i]
+	}
+
+	return child
 }
 
-// With gets zerolog.Context
-func (x *Logger) With() zerolog.Context {
-	return x.zeroLogger.With()
+// Name returns the dotted name of the logger.
+func (x *Logger) Name() string {
+	return x.name
+}
+
+// badKey is the key for values whose key is missing or not a string.
+const badKey = "!BADKEY"
+
+// numberedBadKey returns the nth badKey, which is badKey itself for 0.
+func numberedBadKey(n int) string {
+	if n == 0 {
+		return badKey
+	}
+
+	return badKey + strconv.Itoa(n)
+}
+
+// forEachKeyval calls fn for each key and value pair in keyvals.
+// Values whose key is missing or not a string are passed with badKey numbered from bad,
+// such as "!BADKEY", "!BADKEY1" and "!BADKEY2" from 0, so that they do not replace each other.
+func forEachKeyval(keyvals []interface{}, bad int, fn func(key string, value interface{})) {
+	for i := 0; i < len(keyvals); i++ {
+		key, ok := keyvals[i].(string)
+		if !ok || i+1 == len(keyvals) {
+			fn(numberedBadKey(bad), keyvals[i])
+			bad++
+
+			continue
+		}
+
+		fn(key, keyvals[i+1])
+		i++
+	}
 }
@@ -10,37 +10,163 @@ import (
 var (
 	envLogLevel  = os.Getenv("LOG_LEVEL")  // nolint:gochecknoglobals
 	envLogFormat = os.Getenv("LOG_FORMAT") // nolint:gochecknoglobals
+	envLogCaller = os.Getenv("LOG_CALLER") // nolint:gochecknoglobals
+	envLogOutput = os.Getenv("LOG_OUTPUT") // nolint:gochecknoglobals
+	envLogSinks  = os.Getenv("LOG_SINKS")  // nolint:gochecknoglobals
 )
 
 // New returns a new Logger.
 func New() *Logger {
 	return NewWithOption(
 		OptionLevel(envLogLevel),
+		OptionOutput(envLogOutput),
 		OptionWriter(envLogFormat),
+		optionSinksEnv(envLogSinks),
+		optionCallerEnv(envLogCaller),
 	)
 }
 
 // NewWithOption returns a new Logger with options.
+// If any option is invalid, it is ignored and a warning is written to the logger.
 func NewWithOption(opts ...OptionFunc) *Logger {
+	logger, err := NewWithOptionE(opts...)
+	if err != nil {
+		logger.E(err).Warn("invalid logger option is ignored")
+	}
+
+	return logger
+}
+
+// NewWithOptionE returns a new Logger with options.
+// If any option is invalid, it returns the error along with a Logger built from the valid options.
+func NewWithOptionE(opts ...OptionFunc) (*Logger, error) {
 	opt := &Option{
-		Level:  zerolog.InfoLevel,
-		Writer: os.Stdout,
+		Level:           zerolog.InfoLevel,
+		Writer:          jsonFormat(os.Stdout),
+		StacktraceLevel: zerolog.Disabled,
+		format:          jsonFormat,
 	}
 
 	for _, fn := range opts {
 		fn(opt)
 	}
 
-	logger := zerolog.New(opt.Writer).Level(opt.Level).With().Timestamp().Logger()
+	if len(opt.sinks) > 0 {
+		if router, err := newLevelRouter(opt.sinks); err != nil {
+			opt.addError(err)
+		} else {
+			opt.Writer = router
+
+			if !opt.levelSet && opt.Level == zerolog.InfoLevel {
+				opt.Level = router.minLevel()
+			}
+		}
+	}
+
+	if opt.async != nil {
+		opt.Writer = NewAsyncWriter(opt.Writer, *opt.async)
+	}
+
+	level := opt.AtomicLevel
+	if level == nil {
+		level = NewAtomicLevel(opt.Level)
+	}
+
+	components := make(map[string]*AtomicLevel, len(opt.ComponentLevels))
+	for name, componentLevel := range opt.ComponentLevels {
+		components[name] = NewAtomicLevel(componentLevel)
+	}
+
+	logger := zerolog.New(opt.Writer).With().Timestamp().Logger()
 
 	return &Logger{
 		zeroLogger: logger,
-	}
+		level:      level,
+		caller:     callerConfig{enabled: opt.Caller, fn: opt.CallerFunc, skip: opt.CallerSkip},
+		core: &loggerCore{
+			writer:       opt.Writer,
+			exitFunc:     opt.ExitFunc,
+			scoped:       wantsScope(opt.Writer),
+			reportErrors: wantsErrorCaller(opt.Writer),
+		},
+		components: components,
+
+		stacktraceLevel: opt.StacktraceLevel,
+	}, opt.err()
 }
 
 type Option struct {
 	Level  zerolog.Level
 	Writer io.Writer
+
+	// ComponentLevels holds the levels of named loggers by dotted name.
+	ComponentLevels map[string]zerolog.Level
+
+	// Caller annotates messages with the caller, and CallerFunc with its function as well.
+	// CallerSkip is the number of extra stack frames to skip.
+	Caller     bool
+	CallerFunc bool
+	CallerSkip int
+
+	// StacktraceLevel is the level at and above which messages have a stack trace.
+	StacktraceLevel zerolog.Level
+
+	// ExitFunc is called by Fatal to exit. The default is os.Exit.
+	ExitFunc func(code int)
+
+	// AtomicLevel is shared with the Logger instead of Level if it is set.
+	AtomicLevel *AtomicLevel
+
+	errs []error
+
+	// levelSet is whether Level is set by OptionLevel.
+	levelSet bool
+
+	// output is the destination of format. The default is os.Stdout.
+	output io.Writer
+	format FormatFactory
+	async  *AsyncConfig
+	sinks  []Sink
+}
+
+// setFormat sets the writer to format the output.
+func (x *Option) setFormat(format FormatFactory) {
+	x.format = format
+	x.Writer = format(x.outputWriter())
+}
+
+// setOutput sets the writer to output the format.
+func (x *Option) setOutput(output io.Writer) {
+	x.output = output
+
+	if x.format != nil {
+		x.Writer = x.format(output)
+	} else {
+		x.Writer = output
+	}
+}
+
+// outputWriter returns the destination of format.
+func (x *Option) outputWriter() io.Writer {
+	if x.output == nil {
+		return os.Stdout
+	}
+
+	return x.output
+}
+
+// addError records an invalid configuration.
+func (x *Option) addError(err error) {
+	x.errs = append(x.errs, err)
+}
+
+// err returns the first invalid configuration, if any.
+func (x *Option) err() error {
+	if len(x.errs) == 0 {
+		return nil
+	}
+
+	return x.errs[0]
 }
 
 type OptionFunc func(opt *Option)
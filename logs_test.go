@@ -2,12 +2,19 @@ package logs_test
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"path/filepath"
+	"runtime"
 	"strconv"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
+	pkgerrors "github.com/pkg/errors"
 	"github.com/rs/zerolog"
 	logs "github.com/rtkym/logs-go"
 	"github.com/stretchr/testify/assert"
@@ -52,6 +59,40 @@ const (
 	LevelFatalValue = "fatal"
 )
 
+// jsonWriter returns a writer of JSON lines to w, which converts CBOR into JSON in binary_log builds.
+func jsonWriter(w io.Writer) io.Writer {
+	return logs.ExpNewFormatWriter("json", w)
+}
+
+// formatOutputs returns the outputs of log written by loggers of format, configured by OptionWriter and by LOG_FORMAT.
+func formatOutputs(t *testing.T, format string, log func(logger *logs.Logger)) map[string]string {
+	t.Helper()
+
+	dir := t.TempDir()
+	outputs := map[string]string{}
+
+	for name, newLogger := range map[string]func(output string) *logs.Logger{
+		"OptionWriter": func(output string) *logs.Logger {
+			return logs.NewWithOption(logs.OptionOutput(output), logs.OptionWriter(format))
+		},
+		"LOG_FORMAT": func(output string) *logs.Logger {
+			defer logs.ExpSetLogFormat(format)()
+			defer logs.ExpSetLogOutput(output)()
+
+			return logs.New()
+		},
+	} {
+		path := filepath.Join(dir, name+".log")
+		logger := newLogger("file://" + path)
+		log(logger)
+		assert.NoError(t, logger.Close())
+
+		outputs[name] = readFile(t, path)
+	}
+
+	return outputs
+}
+
 func testExec(t *testing.T, testee func(msg string), level Level, threshold Level, buf *bytes.Buffer) {
 	t.Helper()
 
@@ -72,7 +113,7 @@ func TestGlobalLogger(t *testing.T) {
 		level := TraceLevel
 
 		buf := &bytes.Buffer{}
-		logs.GlobalLoggerOptions = []logs.OptionFunc{logs.OptionLevel(level.String()), func(opt *logs.Option) { opt.Writer = buf }}
+		logs.GlobalLoggerOptions = []logs.OptionFunc{logs.OptionLevel(level.String()), func(opt *logs.Option) { opt.Writer = jsonWriter(buf) }}
 		logs.InitGlobalLogger()
 
 		testExec(t, logs.Trace, TraceLevel, level, buf)
@@ -86,7 +127,7 @@ func TestGlobalLogger(t *testing.T) {
 		level := DebugLevel
 
 		buf := &bytes.Buffer{}
-		logs.GlobalLoggerOptions = []logs.OptionFunc{logs.OptionLevel(level.String()), func(opt *logs.Option) { opt.Writer = buf }}
+		logs.GlobalLoggerOptions = []logs.OptionFunc{logs.OptionLevel(level.String()), func(opt *logs.Option) { opt.Writer = jsonWriter(buf) }}
 		logs.InitGlobalLogger()
 
 		testExec(t, logs.Trace, TraceLevel, level, buf)
@@ -100,7 +141,7 @@ func TestGlobalLogger(t *testing.T) {
 		level := InfoLevel
 
 		buf := &bytes.Buffer{}
-		logs.GlobalLoggerOptions = []logs.OptionFunc{logs.OptionLevel(level.String()), func(opt *logs.Option) { opt.Writer = buf }}
+		logs.GlobalLoggerOptions = []logs.OptionFunc{logs.OptionLevel(level.String()), func(opt *logs.Option) { opt.Writer = jsonWriter(buf) }}
 		logs.InitGlobalLogger()
 
 		testExec(t, logs.Trace, TraceLevel, level, buf)
@@ -114,7 +155,7 @@ func TestGlobalLogger(t *testing.T) {
 		level := WarnLevel
 
 		buf := &bytes.Buffer{}
-		logs.GlobalLoggerOptions = []logs.OptionFunc{logs.OptionLevel(level.String()), func(opt *logs.Option) { opt.Writer = buf }}
+		logs.GlobalLoggerOptions = []logs.OptionFunc{logs.OptionLevel(level.String()), func(opt *logs.Option) { opt.Writer = jsonWriter(buf) }}
 		logs.InitGlobalLogger()
 
 		testExec(t, logs.Trace, TraceLevel, level, buf)
@@ -128,7 +169,7 @@ func TestGlobalLogger(t *testing.T) {
 		level := ErrorLevel
 
 		buf := &bytes.Buffer{}
-		logs.GlobalLoggerOptions = []logs.OptionFunc{logs.OptionLevel(level.String()), func(opt *logs.Option) { opt.Writer = buf }}
+		logs.GlobalLoggerOptions = []logs.OptionFunc{logs.OptionLevel(level.String()), func(opt *logs.Option) { opt.Writer = jsonWriter(buf) }}
 		logs.InitGlobalLogger()
 
 		testExec(t, logs.Trace, TraceLevel, level, buf)
@@ -142,7 +183,7 @@ func TestGlobalLogger(t *testing.T) {
 		level := InfoLevel
 
 		buf := &bytes.Buffer{}
-		logs.GlobalLoggerOptions = []logs.OptionFunc{logs.OptionLevel(level.String()), func(opt *logs.Option) { opt.Writer = buf }}
+		logs.GlobalLoggerOptions = []logs.OptionFunc{logs.OptionLevel(level.String()), func(opt *logs.Option) { opt.Writer = jsonWriter(buf) }}
 		logs.InitGlobalLogger()
 
 		logs.Set("set1", "a")
@@ -171,7 +212,7 @@ func TestGlobalLogger(t *testing.T) {
 
 		assert.Contains(t, buf.String(), `"set1":"a"`)
 		assert.Contains(t, buf.String(), `"set2":"b"`)
-		assert.Contains(t, buf.String(), `"error":{"message":"test error"}`)
+		assert.Contains(t, buf.String(), `"error":{"message":"test error","type":"*errors.errorString"}`)
 		assert.Contains(t, buf.String(), `"test msg3"`)
 	})
 }
@@ -181,7 +222,7 @@ func TestLogger(t *testing.T) {
 		level := TraceLevel
 
 		buf := &bytes.Buffer{}
-		logger := logs.NewWithOption(logs.OptionLevel(level.String()), func(opt *logs.Option) { opt.Writer = buf })
+		logger := logs.NewWithOption(logs.OptionLevel(level.String()), func(opt *logs.Option) { opt.Writer = jsonWriter(buf) })
 
 		testExec(t, logger.Trace, TraceLevel, level, buf)
 		testExec(t, logger.Debug, DebugLevel, level, buf)
@@ -194,7 +235,7 @@ func TestLogger(t *testing.T) {
 		level := DebugLevel
 
 		buf := &bytes.Buffer{}
-		logger := logs.NewWithOption(logs.OptionLevel(level.String()), func(opt *logs.Option) { opt.Writer = buf })
+		logger := logs.NewWithOption(logs.OptionLevel(level.String()), func(opt *logs.Option) { opt.Writer = jsonWriter(buf) })
 
 		testExec(t, logger.Trace, TraceLevel, level, buf)
 		testExec(t, logger.Debug, DebugLevel, level, buf)
@@ -207,7 +248,7 @@ func TestLogger(t *testing.T) {
 		level := InfoLevel
 
 		buf := &bytes.Buffer{}
-		logger := logs.NewWithOption(logs.OptionLevel(level.String()), func(opt *logs.Option) { opt.Writer = buf })
+		logger := logs.NewWithOption(logs.OptionLevel(level.String()), func(opt *logs.Option) { opt.Writer = jsonWriter(buf) })
 
 		testExec(t, logger.Trace, TraceLevel, level, buf)
 		testExec(t, logger.Debug, DebugLevel, level, buf)
@@ -220,7 +261,7 @@ func TestLogger(t *testing.T) {
 		level := WarnLevel
 
 		buf := &bytes.Buffer{}
-		logger := logs.NewWithOption(logs.OptionLevel(level.String()), func(opt *logs.Option) { opt.Writer = buf })
+		logger := logs.NewWithOption(logs.OptionLevel(level.String()), func(opt *logs.Option) { opt.Writer = jsonWriter(buf) })
 
 		testExec(t, logger.Trace, TraceLevel, level, buf)
 		testExec(t, logger.Debug, DebugLevel, level, buf)
@@ -233,7 +274,7 @@ func TestLogger(t *testing.T) {
 		level := ErrorLevel
 
 		buf := &bytes.Buffer{}
-		logger := logs.NewWithOption(logs.OptionLevel(level.String()), func(opt *logs.Option) { opt.Writer = buf })
+		logger := logs.NewWithOption(logs.OptionLevel(level.String()), func(opt *logs.Option) { opt.Writer = jsonWriter(buf) })
 
 		testExec(t, logger.Trace, TraceLevel, level, buf)
 		testExec(t, logger.Debug, DebugLevel, level, buf)
@@ -246,7 +287,7 @@ func TestLogger(t *testing.T) {
 		level := InfoLevel
 
 		buf := &bytes.Buffer{}
-		logger := logs.NewWithOption(logs.OptionLevel(level.String()), func(opt *logs.Option) { opt.Writer = buf })
+		logger := logs.NewWithOption(logs.OptionLevel(level.String()), func(opt *logs.Option) { opt.Writer = jsonWriter(buf) })
 
 		logger.Set("set1", "a")
 		logger.Set("set2", "b")
@@ -274,7 +315,7 @@ func TestLogger(t *testing.T) {
 
 		assert.Contains(t, buf.String(), `"set1":"a"`)
 		assert.Contains(t, buf.String(), `"set2":"b"`)
-		assert.Contains(t, buf.String(), `"error":{"message":"test error"}`)
+		assert.Contains(t, buf.String(), `"error":{"message":"test error","type":"*errors.errorString"}`)
 		assert.Contains(t, buf.String(), `"test msg3"`)
 	})
 
@@ -282,7 +323,7 @@ func TestLogger(t *testing.T) {
 		level := InfoLevel
 
 		buf := &bytes.Buffer{}
-		logger := logs.NewWithOption(logs.OptionLevel(level.String()), func(opt *logs.Option) { opt.Writer = buf })
+		logger := logs.NewWithOption(logs.OptionLevel(level.String()), func(opt *logs.Option) { opt.Writer = jsonWriter(buf) })
 
 		buf.Reset()
 		logger.E(&MarshalableError{}).Info("test msg1")
@@ -369,3 +410,875 @@ func TestNewJSON(t *testing.T) {
 
 	assert.NotNil(t, logger)
 }
+
+func TestParseLevel(t *testing.T) {
+	for s, want := range map[string]logs.Level{
+		"trace":    logs.TraceLevel,
+		"debug":    logs.DebugLevel,
+		"INFO":     logs.InfoLevel,
+		"warn":     logs.WarnLevel,
+		"warning":  logs.WarnLevel,
+		"error":    logs.ErrorLevel,
+		"err":      logs.ErrorLevel,
+		"fatal":    logs.FatalLevel,
+		"crit":     logs.FatalLevel,
+		"panic":    logs.PanicLevel,
+		"disabled": logs.Disabled,
+		"-1":       logs.TraceLevel,
+		"3":        logs.ErrorLevel,
+		"7":        logs.Disabled,
+	} {
+		level, err := logs.ParseLevel(s)
+
+		assert.NoError(t, err, s)
+		assert.Equal(t, want, level, s)
+	}
+
+	for _, s := range []string{"", "infoo", "6", "-2", "128"} {
+		_, err := logs.ParseLevel(s)
+
+		assert.ErrorIs(t, err, logs.ErrInvalidLevel, s)
+	}
+}
+
+func TestNewWithOptionE(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger, err := logs.NewWithOptionE(logs.OptionLevel("warning"), func(opt *logs.Option) { opt.Writer = jsonWriter(buf) })
+
+		assert.NoError(t, err)
+		testExec(t, logger.Info, InfoLevel, WarnLevel, buf)
+		testExec(t, logger.Warn, WarnLevel, WarnLevel, buf)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger, err := logs.NewWithOptionE(logs.OptionLevel("dbug"), func(opt *logs.Option) { opt.Writer = jsonWriter(buf) })
+
+		assert.ErrorIs(t, err, logs.ErrInvalidLevel)
+		testExec(t, logger.Debug, DebugLevel, InfoLevel, buf)
+		testExec(t, logger.Info, InfoLevel, InfoLevel, buf)
+	})
+
+	t.Run("NewWithOption", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger := logs.NewWithOption(logs.OptionLevel("dbug"), func(opt *logs.Option) { opt.Writer = jsonWriter(buf) })
+
+		assert.Contains(t, buf.String(), `"level":"warn"`)
+		assert.Contains(t, buf.String(), `invalid log level: \"dbug\"`)
+		testExec(t, logger.Debug, DebugLevel, InfoLevel, buf)
+	})
+}
+
+func TestOptionWriter(t *testing.T) {
+	t.Run("console", func(t *testing.T) {
+		defer logs.ExpSetLogFormat("json")()
+
+		opt := &logs.Option{}
+		logs.OptionWriter("Console")(opt)
+
+		assert.IsType(t, &zerolog.ConsoleWriter{}, opt.Writer)
+	})
+
+	t.Run("registered", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logs.RegisterFormat("test-upper", func(w io.Writer) io.Writer {
+			return writerFunc(func(p []byte) (int, error) {
+				return buf.Write(bytes.ToUpper(p))
+			})
+		})
+
+		logger, err := logs.NewWithOptionE(logs.OptionWriter("test-upper"))
+		logger.Info("test msg")
+
+		assert.NoError(t, err)
+		assert.Contains(t, logs.Formats(), "test-upper")
+		assert.Contains(t, buf.String(), `"MESSAGE":"TEST MSG"`)
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		_, err := logs.NewWithOptionE(logs.OptionWriter("xml"))
+
+		assert.ErrorIs(t, err, logs.ErrUnknownFormat)
+	})
+}
+
+type writerFunc func(p []byte) (int, error)
+
+func (fn writerFunc) Write(p []byte) (int, error) { return fn(p) }
+
+func TestAtomicLevel(t *testing.T) {
+	t.Run("SetLevel", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger := logs.NewWithOption(func(opt *logs.Option) { opt.Writer = jsonWriter(buf) })
+
+		assert.Equal(t, logs.InfoLevel, logger.Level())
+		testExec(t, logger.Debug, DebugLevel, InfoLevel, buf)
+
+		logger.SetLevel(logs.DebugLevel)
+
+		assert.Equal(t, logs.DebugLevel, logger.Level())
+		testExec(t, logger.Debug, DebugLevel, DebugLevel, buf)
+		testExec(t, logger.Trace, TraceLevel, DebugLevel, buf)
+
+		logger.Set("set1", "a")
+		logger.SetLevel(logs.ErrorLevel)
+
+		testExec(t, logger.Warn, WarnLevel, ErrorLevel, buf)
+		testExec(t, logger.Error, ErrorLevel, ErrorLevel, buf)
+	})
+
+	t.Run("shared", func(t *testing.T) {
+		level := logs.NewAtomicLevel(logs.WarnLevel)
+		buf1 := &bytes.Buffer{}
+		logger1 := logs.NewWithOption(logs.OptionAtomicLevel(level), func(opt *logs.Option) { opt.Writer = jsonWriter(buf1) })
+		buf2 := &bytes.Buffer{}
+		logger2 := logs.NewWithOption(logs.OptionAtomicLevel(level), func(opt *logs.Option) { opt.Writer = jsonWriter(buf2) })
+
+		testExec(t, logger1.Info, InfoLevel, WarnLevel, buf1)
+		testExec(t, logger2.Info, InfoLevel, WarnLevel, buf2)
+
+		logger1.SetLevel(logs.InfoLevel)
+
+		assert.Equal(t, logs.InfoLevel, level.Level())
+		testExec(t, logger1.Info, InfoLevel, InfoLevel, buf1)
+		testExec(t, logger2.Info, InfoLevel, InfoLevel, buf2)
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		logger := logs.NewWithOption(func(opt *logs.Option) { opt.Writer = io.Discard })
+
+		var wg sync.WaitGroup
+
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				for j := 0; j < 1000; j++ {
+					logger.V("j", j).Debug("test msg")
+				}
+			}()
+		}
+
+		for i := 0; i < 1000; i++ {
+			logger.SetLevel(logs.Level(i%3 - 1))
+		}
+
+		wg.Wait()
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		codes := []int{}
+		logger := logs.NewWithOption(
+			logs.OptionLevel("disabled"),
+			logs.OptionExitFunc(func(code int) { codes = append(codes, code) }),
+			func(opt *logs.Option) { opt.Writer = buf },
+		)
+
+		logger.Fatal("test msg")
+		logger.Entry().Fatalf("test %s", "msg")
+
+		assert.Equal(t, []int{1, 1}, codes)
+		assert.PanicsWithValue(t, "test msg", func() { logger.Panic("test msg") })
+		assert.Empty(t, buf.String())
+	})
+}
+
+func TestNamed(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger, err := logs.NewWithOptionE(logs.OptionLevel("info,db=debug,db.pool=trace,http=warn"), func(opt *logs.Option) { opt.Writer = jsonWriter(buf) })
+
+	assert.NoError(t, err)
+
+	db := logger.Named("db")
+	pool := db.Named("pool")
+	conn := logger.Named("db.conn")
+	httpLogger := logger.Named("http")
+	other := logger.Named("other")
+
+	assert.Equal(t, "db.pool", pool.Name())
+	assert.Equal(t, logs.InfoLevel, logger.Level())
+	assert.Equal(t, logs.DebugLevel, db.Level())
+	assert.Equal(t, logs.TraceLevel, pool.Level())
+	assert.Equal(t, logs.DebugLevel, conn.Level())
+	assert.Equal(t, logs.WarnLevel, httpLogger.Level())
+	assert.Equal(t, logs.InfoLevel, other.Level())
+
+	testExec(t, logger.Debug, DebugLevel, InfoLevel, buf)
+	testExec(t, db.Debug, DebugLevel, DebugLevel, buf)
+	testExec(t, pool.Trace, TraceLevel, TraceLevel, buf)
+	testExec(t, httpLogger.Info, InfoLevel, WarnLevel, buf)
+
+	buf.Reset()
+	pool.Info("test msg")
+
+	assert.Contains(t, buf.String(), `"logger":"db.pool"`)
+
+	buf.Reset()
+	logger.Info("test msg")
+
+	assert.NotContains(t, buf.String(), `"logger"`)
+
+	db.SetLevel(logs.ErrorLevel)
+
+	assert.Equal(t, logs.ErrorLevel, conn.Level())
+	assert.Equal(t, logs.TraceLevel, pool.Level())
+
+	logger.SetLevel(logs.WarnLevel)
+
+	assert.Equal(t, logs.WarnLevel, other.Level())
+
+	_, err = logs.NewWithOptionE(logs.OptionLevel("info,=debug"))
+
+	assert.ErrorIs(t, err, logs.ErrInvalidLevel)
+}
+
+func TestWith(t *testing.T) {
+	t.Run("child", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger := logs.NewWithOption(func(opt *logs.Option) { opt.Writer = jsonWriter(buf) })
+		logger.Set("set1", "a")
+
+		child := logger.With("with1", "1", "with2", 2)
+
+		buf.Reset()
+		child.Info("test msg1")
+
+		assert.Contains(t, buf.String(), `"set1":"a"`)
+		assert.Contains(t, buf.String(), `"with1":"1"`)
+		assert.Contains(t, buf.String(), `"with2":2`)
+
+		buf.Reset()
+		logger.Info("test msg2")
+
+		assert.Contains(t, buf.String(), `"set1":"a"`)
+		assert.NotContains(t, buf.String(), `"with1"`)
+
+		logger.Set("set2", "b")
+		logger.SetLevel(logs.WarnLevel)
+
+		buf.Reset()
+		child.Warn("test msg3")
+
+		assert.NotContains(t, buf.String(), `"set2"`)
+		testExec(t, child.Info, InfoLevel, WarnLevel, buf)
+	})
+
+	t.Run("bad key", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger := logs.NewWithOption(func(opt *logs.Option) { opt.Writer = jsonWriter(buf) })
+
+		logger.With(1, "one", "two").Info("test msg")
+
+		assert.Contains(t, buf.String(), `"!BADKEY":1,"one":"two"`)
+
+		buf.Reset()
+		logger.With("one", 1, "two").Info("test msg")
+
+		assert.Contains(t, buf.String(), `"one":1,"!BADKEY":"two"`)
+
+		buf.Reset()
+		logger.With(1, 2, "one", 1, 3).Info("test msg")
+
+		assert.Contains(t, buf.String(), `"!BADKEY":1,"!BADKEY1":2,"one":1,"!BADKEY2":3`)
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		logger := logs.NewWithOption(func(opt *logs.Option) { opt.Writer = io.Discard })
+
+		var wg sync.WaitGroup
+
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+
+			go func(i int) {
+				defer wg.Done()
+
+				child := logger.With("goroutine", i)
+
+				for j := 0; j < 100; j++ {
+					logger.Info("test msg")
+					child.Info("test msg")
+					logger.Set("j", j)
+				}
+			}(i)
+		}
+
+		wg.Wait()
+	})
+}
+
+func TestEntryOrder(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := logs.NewWithOption(func(opt *logs.Option) { opt.Writer = jsonWriter(buf) })
+
+	logger.V("c", 1).V("a", 2).V("b", 3).V("a", 4).E(errors.New("test error")).Info("test msg")
+
+	assert.Contains(t, buf.String(), `"c":1,"a":4,"b":3,"error":{"message":"test error","type":"*errors.errorString"}`)
+}
+
+func TestEntryTyped(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := logs.NewWithOption(func(opt *logs.Option) { opt.Writer = jsonWriter(buf) })
+
+	logger.Entry().
+		Str("str", "a").
+		Int("int", 1).
+		Int64("int64", 2).
+		Float("float", 1.5).
+		Bool("bool", true).
+		Dur("dur", 1500*time.Millisecond).
+		Time("time", time.Date(2022, 8, 16, 14, 5, 47, 0, time.UTC)).
+		Bytes("bytes", []byte("b")).
+		Strs("strs", []string{"x", "y"}).
+		Stringer("stringer", time.Second).
+		Stringer("nil", nil).
+		V("v", 3).
+		Info("test msg")
+
+	assert.Contains(t, buf.String(), `"str":"a","int":1,"int64":2,"float":1.5,"bool":true,"dur":1500,`+
+		`"time":"2022-08-16T14:05:47.000000000Z","bytes":"b","strs":["x","y"],"stringer":"1s","nil":null,"v":3`)
+
+	buf.Reset()
+	logger.Entry().Str("key", "a").Int("key", 1).Info("test msg")
+
+	assert.Contains(t, buf.String(), `"key":1`)
+	assert.NotContains(t, buf.String(), `"key":"a"`)
+}
+
+func TestEntryPool(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := logs.NewWithOption(func(opt *logs.Option) { opt.Writer = jsonWriter(buf) })
+
+	for i := 0; i < 10; i++ {
+		logger.V("disabled", i).E(errors.New("test error")).Debug("test msg")
+		logger.V("enabled", i).Info("test msg")
+	}
+
+	buf.Reset()
+	logger.Info("test msg")
+
+	assert.NotContains(t, buf.String(), `"disabled"`)
+	assert.NotContains(t, buf.String(), `"enabled"`)
+	assert.NotContains(t, buf.String(), `"error"`)
+
+	buf.Reset()
+	logger.E(nil).Info("test msg")
+
+	assert.Contains(t, buf.String(), `"error":null`)
+}
+
+func TestFormatted(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := logs.NewWithOption(func(opt *logs.Option) { opt.Writer = jsonWriter(buf) })
+
+	testExec(t, func(msg string) { logger.Debugf("%s", msg) }, DebugLevel, InfoLevel, buf)
+	testExec(t, func(msg string) { logger.Infof("%s", msg) }, InfoLevel, InfoLevel, buf)
+	testExec(t, func(msg string) { logger.Entry().Warnf("%s", msg) }, WarnLevel, InfoLevel, buf)
+	testExec(t, func(msg string) { logger.Tracew(msg) }, TraceLevel, InfoLevel, buf)
+	testExec(t, func(msg string) { logger.Errorw(msg) }, ErrorLevel, InfoLevel, buf)
+
+	buf.Reset()
+	logger.Infof("%d items in %s", 3, "box")
+
+	assert.Contains(t, buf.String(), `"message":"3 items in box"`)
+
+	buf.Reset()
+	logger.Infow("test msg", "user", "u1", "n", 3)
+
+	assert.Contains(t, buf.String(), `"user":"u1","n":3`)
+
+	buf.Reset()
+	logger.V("v", 1).Warnw("test msg", 2, "x", "odd")
+
+	assert.Contains(t, buf.String(), `"v":1,"!BADKEY":2,"x":"odd"`)
+
+	buf.Reset()
+	logger.Infow("test msg", "n")
+
+	assert.Contains(t, buf.String(), `"!BADKEY":"n"`)
+	assert.Contains(t, buf.String(), `"message":"test msg"`)
+
+	buf.Reset()
+	logger.Infow("test msg", 1, 2, 3)
+
+	assert.Contains(t, buf.String(), `"!BADKEY":1,"!BADKEY1":2,"!BADKEY2":3`)
+
+	buf.Reset()
+	logger.Entry().KV(1).KV("k", "v", 2).Infow("test msg", 3)
+
+	assert.Contains(t, buf.String(), `"!BADKEY":1,"k":"v","!BADKEY1":2,"!BADKEY2":3`)
+}
+
+func TestGlobalFormatted(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logs.GlobalLoggerOptions = []logs.OptionFunc{func(opt *logs.Option) { opt.Writer = jsonWriter(buf) }}
+	logs.InitGlobalLogger()
+
+	testExec(t, func(msg string) { logs.Debugf("%s", msg) }, DebugLevel, InfoLevel, buf)
+	testExec(t, func(msg string) { logs.Errorf("%s", msg) }, ErrorLevel, InfoLevel, buf)
+
+	buf.Reset()
+	logs.Infow("test msg", "user", "u1", "n", 3)
+
+	assert.Contains(t, buf.String(), `"user":"u1","n":3`)
+}
+
+func TestCaller(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := logs.NewWithOption(logs.OptionCaller(0), func(opt *logs.Option) { opt.Writer = jsonWriter(buf) })
+	logs.GlobalLoggerOptions = []logs.OptionFunc{logs.OptionCallerFunc(), func(opt *logs.Option) { opt.Writer = jsonWriter(buf) }}
+	logs.InitGlobalLogger()
+
+	assertCaller := func(t *testing.T, fn func()) {
+		t.Helper()
+
+		buf.Reset()
+		_, file, line, _ := runtime.Caller(1)
+		fn()
+
+		assert.Contains(t, buf.String(), fmt.Sprintf(`"caller":"%s:%d"`, file, line))
+	}
+
+	assertCaller(t, func() { logger.Info("test msg") })
+	assertCaller(t, func() { logger.Infof("test %s", "msg") })
+	assertCaller(t, func() { logger.Infow("test msg", "k", "v") })
+	assertCaller(t, func() { logger.Entry().Info("test msg") })
+	assertCaller(t, func() { logger.V("k", "v").Warnf("test %s", "msg") })
+	assertCaller(t, func() { logger.With("k", "v").Named("sub").Error("test msg") })
+	assertCaller(t, func() { logs.Info("test msg") })
+	assertCaller(t, func() { logs.Errorw("test msg") })
+	assertCaller(t, func() { logs.V("k", "v").Info("test msg") })
+
+	assert.Contains(t, buf.String(), `"func":"github.com/rtkym/logs-go_test.TestCaller.func`)
+
+	wrapper := func(msg string) { logger.AddCallerSkip(1).Info(msg) }
+
+	assertCaller(t, func() { wrapper("test msg") })
+
+	buf.Reset()
+	logs.NewWithOption(func(opt *logs.Option) { opt.Writer = jsonWriter(buf) }).Info("test msg")
+
+	assert.NotContains(t, buf.String(), `"caller"`)
+}
+
+func TestCallerEnv(t *testing.T) {
+	defer logs.ExpSetLogCaller("func")()
+
+	assert.NotNil(t, logs.New())
+
+	defer logs.ExpSetLogCaller("maybe")()
+
+	_, err := logs.NewWithOptionE(logs.ExpOptionCallerEnv())
+
+	assert.Error(t, err)
+}
+
+type StackError struct {
+	pcs []uintptr
+}
+
+func NewStackError() *StackError {
+	pcs := make([]uintptr, 32)
+
+	return &StackError{pcs: pcs[:runtime.Callers(2, pcs)]}
+}
+
+func (err *StackError) Error() string { return "StackError" }
+
+func (err *StackError) Callers() []uintptr { return err.pcs }
+
+func TestStacktrace(t *testing.T) {
+	t.Run("level", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger := logs.NewWithOption(logs.OptionStacktraceLevel(logs.ErrorLevel), func(opt *logs.Option) { opt.Writer = jsonWriter(buf) })
+
+		buf.Reset()
+		logger.Warn("test msg")
+
+		assert.NotContains(t, buf.String(), `"stack"`)
+
+		buf.Reset()
+		logger.Error("test msg")
+
+		assert.Contains(t, buf.String(), `"stack":[{"func":"github.com/rtkym/logs-go_test.TestStacktrace.func1","file":`)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger := logs.NewWithOption(func(opt *logs.Option) { opt.Writer = jsonWriter(buf) })
+
+		logger.E(fmt.Errorf("wrapped: %w", NewStackError())).Error("test msg")
+
+		assert.Contains(t, buf.String(), `"error":{"message":"wrapped: StackError","type":"*fmt.wrapError","stack":[{"func":"github.com/rtkym/logs-go_test.TestStacktrace.func2"`)
+
+		buf.Reset()
+		logger.E(pkgerrors.New("pkg error")).Error("test msg")
+
+		assert.Contains(t, buf.String(), `"error":{"message":"pkg error","type":"*errors.fundamental","stack":[{"func":"github.com/rtkym/logs-go_test.TestStacktrace.func2"`)
+
+		buf.Reset()
+		logger.E(pkgerrors.Wrap(pkgerrors.New("pkg error"), "wrapped")).Error("test msg")
+
+		assert.Contains(t, buf.String(), `"error":{"message":"wrapped: pkg error","type":"*errors.withStack","stack":[{"func":"github.com/rtkym/logs-go_test.TestStacktrace.func2"`)
+	})
+
+	t.Run("console", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger := logs.NewWithOption(logs.OptionStacktraceLevel(logs.ErrorLevel), func(opt *logs.Option) {
+			logs.OptionConsoleWriter()(opt)
+			opt.Writer.(*zerolog.ConsoleWriter).Out = buf
+			opt.Writer.(*zerolog.ConsoleWriter).NoColor = true
+		})
+
+		logger.E(NewStackError()).Error("test msg")
+
+		assert.Contains(t, buf.String(), `error test msg error={"message":"StackError","type":"*logs_test.StackError"}`)
+		assert.Contains(t, buf.String(), "\nstack:\n\tgithub.com/rtkym/logs-go_test.TestStacktrace.func3\n\t\t")
+		assert.Contains(t, buf.String(), "\nerror stack:\n\tgithub.com/rtkym/logs-go_test.TestStacktrace.func3\n\t\t")
+	})
+}
+
+type FieldsError struct {
+	err error
+}
+
+func (err *FieldsError) Error() string { return "query failed: " + err.err.Error() }
+
+func (err *FieldsError) Unwrap() error { return err.err }
+
+func (err *FieldsError) LogFields() map[string]interface{} {
+	return map[string]interface{}{"table": "users", "rows": 0}
+}
+
+type JoinedError []error
+
+func (err JoinedError) Error() string { return fmt.Sprintf("%d errors", len(err)) }
+
+func (err JoinedError) Unwrap() []error { return err }
+
+func TestErrorSerialization(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := logs.NewWithOption(func(opt *logs.Option) { opt.Writer = jsonWriter(buf) })
+
+	t.Run("chain", func(t *testing.T) {
+		buf.Reset()
+		logger.E(fmt.Errorf("outer: %w", &FieldsError{errors.New("timeout")})).Error("test msg")
+
+		assert.Contains(t, buf.String(), `"error":{"message":"outer: query failed: timeout","type":"*fmt.wrapError","causes":[`+
+			`{"message":"query failed: timeout","type":"*logs_test.FieldsError","fields":{"rows":0,"table":"users"}},`+
+			`{"message":"timeout","type":"*errors.errorString"}]}`)
+	})
+
+	t.Run("joined", func(t *testing.T) {
+		buf.Reset()
+		logger.E(fmt.Errorf("outer: %w", JoinedError{errors.New("e1"), nil, fmt.Errorf("e2: %w", errors.New("e3"))})).Error("test msg")
+
+		assert.Contains(t, buf.String(), `"error":{"message":"outer: 3 errors","type":"*fmt.wrapError","causes":[`+
+			`{"message":"3 errors","type":"logs_test.JoinedError","causes":[`+
+			`{"message":"e1","type":"*errors.errorString"},`+
+			`{"message":"e2: e3","type":"*fmt.wrapError","causes":[{"message":"e3","type":"*errors.errorString"}]}]}]}`)
+	})
+
+	t.Run("EK", func(t *testing.T) {
+		buf.Reset()
+		logger.E(errors.New("e1")).EK("db_error", errors.New("e2")).EK("cache_error", nil).Error("test msg")
+
+		assert.Contains(t, buf.String(), `"error":{"message":"e1","type":"*errors.errorString"},`+
+			`"db_error":{"message":"e2","type":"*errors.errorString"},"cache_error":null`)
+	})
+
+	t.Run("console", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger := logs.NewWithOption(func(opt *logs.Option) {
+			logs.OptionConsoleWriter()(opt)
+			opt.Writer.(*zerolog.ConsoleWriter).Out = buf
+		})
+
+		logger.EK("db_error", NewStackError()).Error("test msg")
+
+		assert.Contains(t, buf.String(), `{db_error:{"message":"StackError","type":"*logs_test.StackError"}}`)
+		assert.Contains(t, buf.String(), "\ndb_error stack:\n\tgithub.com/rtkym/logs-go_test.TestErrorSerialization.func5\n")
+	})
+}
+
+func TestPanic(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := logs.NewWithOption(func(opt *logs.Option) { opt.Writer = jsonWriter(buf) })
+
+	assert.PanicsWithValue(t, "test msg", func() { logger.V("k", "v").Panic("test msg") })
+	assert.Contains(t, buf.String(), `"level":"panic","k":"v"`)
+
+	buf.Reset()
+
+	assert.PanicsWithValue(t, "test 1", func() { logger.Panicf("test %d", 1) })
+	assert.Contains(t, buf.String(), `"message":"test 1"`)
+
+	buf.Reset()
+	logs.GlobalLoggerOptions = []logs.OptionFunc{func(opt *logs.Option) { opt.Writer = jsonWriter(buf) }}
+	logs.InitGlobalLogger()
+
+	assert.PanicsWithValue(t, "test msg", func() { logs.Panicw("test msg", "k", 1) })
+	assert.Contains(t, buf.String(), `"level":"panic","k":1`)
+}
+
+func TestRecover(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := logs.NewWithOption(logs.OptionCallerFunc(), func(opt *logs.Option) { opt.Writer = jsonWriter(buf) })
+
+	t.Run("Recover", func(t *testing.T) {
+		buf.Reset()
+
+		func() {
+			defer logger.Recover()
+
+			panic("boom")
+		}()
+
+		assert.Contains(t, buf.String(), `"level":"error"`)
+		assert.Contains(t, buf.String(), `"func":"github.com/rtkym/logs-go_test.TestRecover.func2.1"`)
+		assert.Contains(t, buf.String(), `"stack":[{"func":"github.com/rtkym/logs-go_test.TestRecover.func2.1"`)
+		assert.Contains(t, buf.String(), `"panic":"boom"`)
+		assert.Contains(t, buf.String(), `"message":"recovered from panic"`)
+	})
+
+	t.Run("runtime error", func(t *testing.T) {
+		buf.Reset()
+
+		func() {
+			defer logger.Recover()
+
+			var m map[string]int
+			m["a"]++
+		}()
+
+		assert.Contains(t, buf.String(), `"stack":[{"func":"github.com/rtkym/logs-go_test.TestRecover.func3.1"`)
+		assert.Contains(t, buf.String(), `"panic":"assignment to entry in nil map"`)
+		assert.Contains(t, buf.String(), `"error":{"message":"assignment to entry in nil map"`)
+	})
+
+	t.Run("RecoverAndPanic", func(t *testing.T) {
+		buf.Reset()
+
+		assert.PanicsWithValue(t, "boom", func() {
+			defer logger.RecoverAndPanic()
+
+			panic("boom")
+		})
+		assert.Contains(t, buf.String(), `"panic":"boom"`)
+	})
+
+	t.Run("Go", func(t *testing.T) {
+		lines := make(chan string, 1)
+		logger := logs.NewWithOption(func(opt *logs.Option) {
+			opt.Writer = jsonWriter(writerFunc(func(p []byte) (int, error) {
+				lines <- string(p)
+
+				return len(p), nil
+			}))
+		})
+
+		logger.Go(func() { panic(errors.New("test error")) })
+
+		select {
+		case line := <-lines:
+			assert.Contains(t, line, `"panic":"test error"`)
+			assert.Contains(t, line, `"message":"recovered from panic"`)
+		case <-time.After(time.Second):
+			assert.Fail(t, "timeout")
+		}
+	})
+
+	t.Run("global", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logs.GlobalLoggerOptions = []logs.OptionFunc{func(opt *logs.Option) { opt.Writer = jsonWriter(buf) }}
+		logs.InitGlobalLogger()
+
+		func() {
+			defer logs.Recover()
+
+			panic("boom")
+		}()
+
+		assert.Contains(t, buf.String(), `"panic":"boom"`)
+	})
+}
+
+type SyncBuffer struct {
+	bytes.Buffer
+	calls []string
+}
+
+func (buf *SyncBuffer) Write(p []byte) (int, error) {
+	buf.calls = append(buf.calls, "write")
+
+	return buf.Buffer.Write(p)
+}
+
+func (buf *SyncBuffer) Sync() error {
+	buf.calls = append(buf.calls, "sync")
+
+	return nil
+}
+
+func TestFatal(t *testing.T) {
+	buf := &SyncBuffer{}
+	codes := []int{}
+	exitFunc := func(code int) {
+		buf.calls = append(buf.calls, "exit")
+		codes = append(codes, code)
+	}
+	logger := logs.NewWithOption(logs.OptionExitFunc(exitFunc), func(opt *logs.Option) { opt.Writer = jsonWriter(buf) })
+
+	logs.RegisterExitHook(func() { buf.calls = append(buf.calls, "hook1") })
+	logs.RegisterExitHook(func() { buf.calls = append(buf.calls, "hook2") })
+
+	logger.V("k", "v").Fatal("test msg")
+
+	assert.Equal(t, []int{1}, codes)
+	assert.Equal(t, []string{"write", "hook1", "hook2", "sync", "exit"}, buf.calls)
+	assert.Contains(t, buf.String(), `"level":"fatal","k":"v"`)
+
+	buf.calls = nil
+	logger.With("k", "v").Fatalf("test %s", "msg")
+
+	assert.Equal(t, []int{1, 1}, codes)
+	assert.Equal(t, []string{"write", "hook1", "hook2", "sync", "exit"}, buf.calls)
+
+	buf.calls = nil
+	logger.SetLevel(logs.Disabled)
+	logger.Fatalw("test msg")
+
+	assert.Equal(t, []int{1, 1, 1}, codes)
+	assert.Equal(t, []string{"hook1", "hook2", "sync", "exit"}, buf.calls)
+}
+
+type CloseBuffer struct {
+	SyncBuffer
+	delay time.Duration
+}
+
+func (buf *CloseBuffer) Close() error {
+	time.Sleep(buf.delay)
+	buf.calls = append(buf.calls, "close")
+
+	return nil
+}
+
+func TestClose(t *testing.T) {
+	t.Run("Sync,Close", func(t *testing.T) {
+		buf := &CloseBuffer{}
+		logger := logs.NewWithOption(func(opt *logs.Option) { opt.Writer = buf })
+		child := logger.With("k", "v")
+
+		assert.NoError(t, child.Sync())
+		assert.NoError(t, logger.Close())
+		assert.NoError(t, child.Close())
+		assert.Equal(t, []string{"sync", "sync", "close"}, buf.calls)
+	})
+
+	t.Run("console", func(t *testing.T) {
+		buf := &CloseBuffer{}
+		logger := logs.NewWithOption(func(opt *logs.Option) {
+			logs.OptionConsoleWriter()(opt)
+			opt.Writer.(*zerolog.ConsoleWriter).Out = buf
+		})
+
+		assert.NoError(t, logger.Close())
+		assert.Equal(t, []string{"sync", "close"}, buf.calls)
+	})
+
+	t.Run("stdout", func(t *testing.T) {
+		logger := logs.NewWithOption()
+
+		assert.NoError(t, logger.Sync())
+		assert.NoError(t, logger.Close())
+	})
+
+	t.Run("Shutdown", func(t *testing.T) {
+		buf := &CloseBuffer{}
+		logs.GlobalLoggerOptions = []logs.OptionFunc{func(opt *logs.Option) { opt.Writer = buf }}
+		logs.InitGlobalLogger()
+
+		assert.NoError(t, logs.Sync())
+		assert.NoError(t, logs.Shutdown(context.Background()))
+		assert.Equal(t, []string{"sync", "sync", "close"}, buf.calls)
+	})
+
+	t.Run("Shutdown deadline", func(t *testing.T) {
+		logs.GlobalLoggerOptions = []logs.OptionFunc{func(opt *logs.Option) { opt.Writer = &CloseBuffer{delay: time.Second} }}
+		logs.InitGlobalLogger()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		assert.ErrorIs(t, logs.Shutdown(ctx), context.DeadlineExceeded)
+	})
+}
+
+func BenchmarkEntryV(b *testing.B) {
+	logger := logs.NewWithOption(func(opt *logs.Option) { opt.Writer = io.Discard })
+	now := time.Now()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		logger.Entry().
+			V("str", "a").
+			V("int", i).
+			V("float", 1.5).
+			V("bool", true).
+			V("dur", time.Second).
+			V("time", now).
+			V("strs", []string{"x", "y"}).
+			Info("test msg")
+	}
+}
+
+func BenchmarkEntryTyped(b *testing.B) {
+	logger := logs.NewWithOption(func(opt *logs.Option) { opt.Writer = io.Discard })
+	now := time.Now()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		logger.Entry().
+			Str("str", "a").
+			Int("int", i).
+			Float("float", 1.5).
+			Bool("bool", true).
+			Dur("dur", time.Second).
+			Time("time", now).
+			Strs("strs", []string{"x", "y"}).
+			Info("test msg")
+	}
+}
+
+func BenchmarkEntryDisabled(b *testing.B) {
+	logger := logs.NewWithOption(func(opt *logs.Option) { opt.Writer = io.Discard })
+	err := errors.New("test error")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		logger.Entry().V("str", "a").Str("str2", "b").Int("int", i).E(err).Debug("test msg")
+	}
+}
+
+func BenchmarkEntryEnabled(b *testing.B) {
+	logger := logs.NewWithOption(func(opt *logs.Option) { opt.Writer = io.Discard })
+	err := errors.New("test error")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		logger.Entry().V("str", "a").Str("str2", "b").Int("int", i).E(err).Info("test msg")
+	}
+}
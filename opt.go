@@ -2,6 +2,7 @@ package logs
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
@@ -9,63 +10,138 @@ import (
 )
 
 // OptionLevel returns an OptionFunc for configuring log levels.
+// An empty level keeps the default, and an unknown level is reported as an error by NewWithOptionE.
+//
+// The level may also be a comma-separated spec with levels of named loggers, such as
+// "info,db=debug,db.pool=trace". See Logger.Named.
 func OptionLevel(level string) OptionFunc {
 	return func(opt *Option) {
-		var zerologLevel zerolog.Level
-
-		switch strings.ToLower(level) {
-		case "trace":
-			zerologLevel = zerolog.TraceLevel
-		case "debug":
-			zerologLevel = zerolog.DebugLevel
-		case "info":
-			zerologLevel = zerolog.InfoLevel
-		case "warn":
-			zerologLevel = zerolog.WarnLevel
-		case "error":
-			zerologLevel = zerolog.ErrorLevel
+		if level == "" {
+			return
 		}
 
-		opt.Level = zerologLevel
+		for _, elem := range strings.Split(level, ",") {
+			name, value, component := strings.Cut(elem, "=")
+			if !component {
+				value = name
+			}
+
+			zerologLevel, err := ParseLevel(value)
+			if err != nil {
+				opt.addError(err)
+
+				continue
+			}
+
+			if name = strings.TrimSpace(name); !component {
+				opt.Level = zerologLevel
+				opt.levelSet = true
+			} else if name == "" {
+				opt.addError(fmt.Errorf("%w: %q", ErrInvalidLevel, elem))
+			} else {
+				if opt.ComponentLevels == nil {
+					opt.ComponentLevels = map[string]zerolog.Level{}
+				}
+
+				opt.ComponentLevels[name] = zerologLevel
+			}
+		}
+	}
+}
+
+// OptionAtomicLevel returns an OptionFunc for sharing a runtime-adjustable level.
+// Loggers created with the same AtomicLevel change their level together.
+func OptionAtomicLevel(level *AtomicLevel) OptionFunc {
+	return func(opt *Option) {
+		opt.AtomicLevel = level
 	}
 }
 
 // OptionWriter returns an OptionFunc for configuring log format.
+// The format is looked up among the formats registered by RegisterFormat, and the default is kept when it is empty.
 func OptionWriter(format string) OptionFunc {
 	return func(opt *Option) {
-		switch strings.ToLower(envLogFormat) {
-		case "console":
-			OptionConsoleWriter()(opt)
-		case "json":
-			OptionJSONWriter()(opt)
+		if format == "" {
+			return
 		}
+
+		factory, err := lookupFormat(format)
+		if err != nil {
+			opt.addError(err)
+
+			return
+		}
+
+		opt.setFormat(factory)
 	}
 }
 
+// OptionFormat returns an OptionFunc for configuring log format. It is the same as OptionWriter.
+func OptionFormat(format string) OptionFunc {
+	return OptionWriter(format)
+}
+
 // OptionJSONWriter returns an OptionFunc for configuring json format.
 func OptionJSONWriter() OptionFunc {
 	return func(opt *Option) {
-		opt.Writer = os.Stdout
+		opt.setFormat(jsonFormat)
 	}
 }
 
 // OptionConsoleWriter returns an OptionFunc for configuring console format.
 func OptionConsoleWriter() OptionFunc {
 	return func(opt *Option) {
-		writer := &zerolog.ConsoleWriter{Out: os.Stdout, TimeFormat: zerolog.TimeFieldFormat}
-		writer.FormatLevel = func(i interface{}) string {
-			return fmt.Sprintf("%-5s", i)
-		}
-		writer.FormatMessage = func(i interface{}) string {
-			return fmt.Sprintf("%s", i)
-		}
-		writer.FormatFieldName = func(i interface{}) string {
-			return fmt.Sprintf("{%s:", i)
+		opt.setFormat(func(w io.Writer) io.Writer { return newConsoleWriter(w) })
+	}
+}
+
+// OptionConsoleWriterWith returns an OptionFunc for configuring console format with cfg.
+func OptionConsoleWriterWith(cfg ConsoleConfig) OptionFunc {
+	return func(opt *Option) {
+		opt.setFormat(func(w io.Writer) io.Writer {
+			if cfg.Out != nil {
+				w = cfg.Out
+			}
+
+			return newConsoleWriterWith(w, cfg)
+		})
+	}
+}
+
+// OptionOutput returns an OptionFunc for configuring the destination of the log format.
+// The output is "stdout", "stderr" or a file URL such as "file:///var/log/app.log", and the default is kept when it is empty.
+// A file URL may have the query parameters of RotateConfig, such as "file:///var/log/app.log?max_size_mb=100&compress=true".
+// Loggers writing to the same file share one FileWriter, as OptionFileWriter.
+func OptionOutput(output string) OptionFunc {
+	return func(opt *Option) {
+		if output == "" {
+			return
 		}
-		writer.FormatFieldValue = func(i interface{}) string {
-			return fmt.Sprintf("%v}", i)
+
+		w, err := openOutput(output)
+		if err != nil {
+			opt.addError(err)
+
+			return
 		}
 
-		opt.Writer = writer
+		opt.setOutput(w)
+	}
+}
+
+// openOutput returns the writer of the output, which is "stdout", "stderr" or a file URL.
+func openOutput(output string) (io.Writer, error) {
+	switch strings.ToLower(output) {
+	case "", "stdout":
+		return os.Stdout, nil
+	case "stderr":
+		return os.Stderr, nil
+	}
+
+	path, config, err := parseFileURL(output)
+	if err != nil {
+		return nil, err
 	}
+
+	return openSharedFile(path, config)
 }
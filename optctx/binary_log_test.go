@@ -0,0 +1,22 @@
+//go:build binary_log
+// +build binary_log
+
+package optctx_test
+
+import (
+	"bytes"
+	"testing"
+
+	logs "github.com/rtkym/logs-go"
+	"github.com/stretchr/testify/assert"
+)
+
+// logLines returns the CBOR records written to buf as JSON lines.
+func logLines(t *testing.T, buf *bytes.Buffer) string {
+	t.Helper()
+
+	lines := &bytes.Buffer{}
+	assert.NoError(t, logs.DecodeCBOR(lines, buf))
+
+	return lines.String()
+}
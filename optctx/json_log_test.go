@@ -0,0 +1,16 @@
+//go:build !binary_log
+// +build !binary_log
+
+package optctx_test
+
+import (
+	"bytes"
+	"testing"
+)
+
+// logLines returns the JSON lines written to buf.
+func logLines(t *testing.T, buf *bytes.Buffer) string {
+	t.Helper()
+
+	return buf.String()
+}
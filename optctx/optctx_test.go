@@ -39,6 +39,6 @@ func TestFromContext(t *testing.T) {
 		logger.Info("test")
 
 		assert.NotNil(t, logger)
-		assert.Contains(t, buf.String(), `"message":"test"`)
+		assert.Contains(t, logLines(t, buf), `"message":"test"`)
 	})
 }
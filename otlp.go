@@ -0,0 +1,306 @@
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// nolint:gochecknoglobals
+var (
+	envOTELResourceAttributes = os.Getenv("OTEL_RESOURCE_ATTRIBUTES")
+	envOTELServiceName        = os.Getenv("OTEL_SERVICE_NAME")
+)
+
+// scopeFieldName is the field which separates the fields of the logger, such as those added by Set,
+// from the fields of the message. It is output only to formats implementing scopeWriter.
+const scopeFieldName = "_scope"
+
+// scopeWriter is implemented by writers which need the scope field.
+type scopeWriter interface {
+	scoped() bool
+}
+
+// wantsScope reports whether w needs the scope field.
+func wantsScope(w io.Writer) bool {
+	writer, ok := w.(scopeWriter)
+
+	return ok && writer.scoped()
+}
+
+// stripScope returns p without the scope field.
+func stripScope(p []byte) []byte {
+	if !bytes.Contains(p, scopeMarker) {
+		return p
+	}
+
+	return bytes.Replace(p, scopeMarker, nil, 1)
+}
+
+// newOTLPWriter returns a writer rendering zerolog's JSON lines as records of the log data model
+// of OpenTelemetry in OTLP/JSON to out.
+//
+// The fields of the logger, such as those added by Set, are output as the attributes of "scope" named by
+// Logger.Named, and the fields of the message, such as those added by V, as the "attributes" of the record.
+// The "resource" is read from OTEL_RESOURCE_ATTRIBUTES and OTEL_SERVICE_NAME.
+func newOTLPWriter(out io.Writer) *reshapeWriter {
+	resource := otlpResource(envOTELResourceAttributes, envOTELServiceName)
+
+	writer := newReshapeWriter(out, func(r record) record { return otlpRecord(r, resource) })
+	writer.scope = true
+
+	return writer
+}
+
+// otlpRecord reshapes r into a log record with resource.
+func otlpRecord(r record, resource []interface{}) record {
+	_, scoped := r.get(scopeFieldName)
+	inScope := scoped
+
+	var (
+		level      = zerolog.NoLevel
+		levelText  string
+		attributes = []interface{}{}
+		scope      = record{}
+		scopeAttrs = []interface{}{}
+		out        = record{}
+		trace      = record{}
+	)
+
+	if s, ok := r.getString(zerolog.LevelFieldName); ok {
+		level, _ = zerolog.ParseLevel(s)
+		levelText = s
+	}
+
+	if value, ok := r.get(zerolog.TimestampFieldName); ok {
+		if t, ok := otlpTime(value); ok {
+			out = append(out, recordField{key: "timeUnixNano", value: strconv.FormatInt(t.UnixNano(), 10)})
+		}
+	}
+
+	out = append(out,
+		recordField{key: "severityNumber", value: json.Number(strconv.Itoa(otlpSeverityNumber(level)))},
+		recordField{key: "severityText", value: levelText},
+	)
+
+	if msg, ok := r.get(zerolog.MessageFieldName); ok {
+		out = append(out, recordField{key: "body", value: otlpAnyValue(msg)})
+	}
+
+	stack, hasStack := r.get(zerolog.ErrorStackFieldName)
+
+	for _, f := range r {
+		switch f.key {
+		case zerolog.LevelFieldName, zerolog.TimestampFieldName, zerolog.MessageFieldName, zerolog.ErrorStackFieldName:
+		case scopeFieldName:
+			inScope = false
+		case LoggerFieldName:
+			scope = scope.set("name", f.value)
+		case TraceIDFieldName:
+			trace = trace.set("traceId", f.value)
+		case SpanIDFieldName:
+			trace = trace.set("spanId", f.value)
+		case TraceSampledFieldName:
+			if sampled, _ := f.value.(bool); sampled {
+				trace = trace.set("flags", json.Number("1"))
+			} else {
+				trace = trace.set("flags", json.Number("0"))
+			}
+		case zerolog.CallerFieldName:
+			attributes = append(attributes, otlpCaller(f.value)...)
+		case CallerFuncFieldName:
+			attributes = append(attributes, otlpKeyValue("code.function", f.value))
+		case zerolog.ErrorFieldName:
+			attributes = append(attributes, otlpException(f.value, stack)...)
+			hasStack = false
+		default:
+			if inScope {
+				scopeAttrs = append(scopeAttrs, otlpKeyValue(f.key, f.value))
+			} else {
+				attributes = append(attributes, otlpKeyValue(f.key, f.value))
+			}
+		}
+	}
+
+	if frames, ok := stack.([]interface{}); hasStack && ok {
+		attributes = append(attributes, otlpKeyValue("exception.stacktrace", recordStackTrace(frames)))
+	}
+
+	out = append(out, recordField{key: "attributes", value: attributes})
+	out = append(out, trace...)
+
+	if len(scopeAttrs) > 0 {
+		scope = scope.set("attributes", scopeAttrs)
+	}
+
+	return append(out,
+		recordField{key: "resource", value: record{{key: "attributes", value: resource}}},
+		recordField{key: "scope", value: scope},
+	)
+}
+
+// otlpTime converts the value of the time field.
+func otlpTime(value interface{}) (time.Time, bool) {
+	if t, ok := recordUnixTime(value); ok {
+		return t, true
+	}
+
+	s, ok := value.(string)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	t, err := time.Parse(zerolog.TimeFieldFormat, s)
+
+	return t, err == nil
+}
+
+// otlpSeverityNumber returns the severity number of level.
+func otlpSeverityNumber(level Level) int {
+	switch level {
+	case TraceLevel:
+		return 1 // nolint:gomnd
+	case DebugLevel:
+		return 5 // nolint:gomnd
+	case InfoLevel:
+		return 9 // nolint:gomnd
+	case WarnLevel:
+		return 13 // nolint:gomnd
+	case ErrorLevel:
+		return 17 // nolint:gomnd
+	case FatalLevel:
+		return 21 // nolint:gomnd
+	case PanicLevel:
+		return 22 // nolint:gomnd
+	}
+
+	return 0
+}
+
+// otlpCaller returns the attributes of the caller of "file:line".
+func otlpCaller(value interface{}) []interface{} {
+	caller, ok := value.(string)
+	if !ok {
+		return []interface{}{otlpKeyValue("code.filepath", value)}
+	}
+
+	i := strings.LastIndexByte(caller, ':')
+	if i < 0 {
+		return []interface{}{otlpKeyValue("code.filepath", caller)}
+	}
+
+	attributes := []interface{}{otlpKeyValue("code.filepath", caller[:i])}
+
+	if _, err := strconv.Atoi(caller[i+1:]); err == nil {
+		attributes = append(attributes, otlpKeyValue("code.lineno", json.Number(caller[i+1:])))
+	}
+
+	return attributes
+}
+
+// otlpException returns the attributes of the error object of E, such as "exception.message".
+func otlpException(value interface{}, stack interface{}) []interface{} {
+	var attributes []interface{}
+
+	errObject, ok := value.(record)
+	if !ok {
+		if value != nil {
+			attributes = append(attributes, otlpKeyValue("exception.message", value))
+		}
+	} else {
+		if errStack, ok := errObject.get("stack"); ok {
+			stack = errStack
+		}
+
+		for _, f := range errObject {
+			if f.key != "stack" {
+				attributes = append(attributes, otlpKeyValue("exception."+f.key, f.value))
+			}
+		}
+	}
+
+	if frames, ok := stack.([]interface{}); ok {
+		attributes = append(attributes, otlpKeyValue("exception.stacktrace", recordStackTrace(frames)))
+	}
+
+	return attributes
+}
+
+// otlpKeyValue returns a KeyValue of OTLP/JSON.
+func otlpKeyValue(key string, value interface{}) record {
+	return record{{key: "key", value: key}, {key: "value", value: otlpAnyValue(value)}}
+}
+
+// otlpAnyValue returns an AnyValue of OTLP/JSON.
+func otlpAnyValue(value interface{}) record {
+	switch v := value.(type) {
+	case string:
+		return record{{key: "stringValue", value: v}}
+	case bool:
+		return record{{key: "boolValue", value: v}}
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return record{{key: "intValue", value: strconv.FormatInt(i, 10)}}
+		}
+
+		return record{{key: "doubleValue", value: v}}
+	case []interface{}:
+		values := make([]interface{}, 0, len(v))
+		for _, elem := range v {
+			values = append(values, otlpAnyValue(elem))
+		}
+
+		return record{{key: "arrayValue", value: record{{key: "values", value: values}}}}
+	case record:
+		values := make([]interface{}, 0, len(v))
+		for _, f := range v {
+			values = append(values, otlpKeyValue(f.key, f.value))
+		}
+
+		return record{{key: "kvlistValue", value: record{{key: "values", value: values}}}}
+	}
+
+	return record{}
+}
+
+// otlpResource returns the resource attributes of OTEL_RESOURCE_ATTRIBUTES and OTEL_SERVICE_NAME.
+// The service.name defaults to "unknown_service:<executable>" as the SDKs of OpenTelemetry.
+func otlpResource(attributes, serviceName string) []interface{} {
+	r := record{}
+
+	for _, pair := range strings.Split(attributes, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+
+		if !ok || key == "" {
+			continue
+		}
+
+		if unescaped, err := url.PathUnescape(strings.TrimSpace(value)); err == nil {
+			value = unescaped
+		}
+
+		r = r.set(key, value)
+	}
+
+	if serviceName != "" {
+		r = r.set("service.name", serviceName)
+	} else if _, ok := r.get("service.name"); !ok {
+		r = r.set("service.name", "unknown_service:"+filepath.Base(os.Args[0]))
+	}
+
+	values := make([]interface{}, 0, len(r))
+	for _, f := range r {
+		values = append(values, otlpKeyValue(f.key, f.value))
+	}
+
+	return values
+}
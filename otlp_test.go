@@ -0,0 +1,148 @@
+package logs_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	logs "github.com/rtkym/logs-go"
+	"github.com/stretchr/testify/assert"
+)
+
+// otlpAttributes converts the attributes of OTLP/JSON into a map.
+func otlpAttributes(t *testing.T, v interface{}) map[string]interface{} {
+	t.Helper()
+
+	attributes := map[string]interface{}{}
+
+	list, _ := v.([]interface{})
+	for _, elem := range list {
+		kv, _ := elem.(map[string]interface{})
+		attributes[kv["key"].(string)] = kv["value"]
+	}
+
+	return attributes
+}
+
+func TestOTLPWriter(t *testing.T) {
+	defer logs.ExpSetOTELResource("deployment.environment=prod,team=a%20b", "")()
+
+	buf := &bytes.Buffer{}
+	newLogger := func(opts ...logs.OptionFunc) *logs.Logger {
+		return logs.NewWithOption(append([]logs.OptionFunc{
+			func(opt *logs.Option) { opt.Writer = logs.ExpNewFormatWriter("otlp-json", buf) },
+		}, opts...)...)
+	}
+
+	t.Run("record", func(t *testing.T) {
+		logger := newLogger()
+		logger.Set("tenant", "acme")
+		logger.Named("db").
+			Entry().
+			TraceContext("0123456789abcdef0123456789abcdef", "0123456789abcdef", true).
+			V("rows", 3).
+			V("ratio", 0.5).
+			V("ok", true).
+			V("tags", []string{"a"}).
+			V("obj", map[string]string{"k": "v"}).
+			Warn("test msg")
+
+		res := decodeLine(t, buf)
+		assert.NotEmpty(t, res["timeUnixNano"])
+		assert.Equal(t, float64(13), res["severityNumber"])
+		assert.Equal(t, "warn", res["severityText"])
+		assert.Equal(t, map[string]interface{}{"stringValue": "test msg"}, res["body"])
+		assert.Equal(t, "0123456789abcdef0123456789abcdef", res["traceId"])
+		assert.Equal(t, "0123456789abcdef", res["spanId"])
+		assert.Equal(t, float64(1), res["flags"])
+		assert.NotContains(t, res, "_scope")
+
+		assert.Equal(t, map[string]interface{}{
+			"rows":  map[string]interface{}{"intValue": "3"},
+			"ratio": map[string]interface{}{"doubleValue": 0.5},
+			"ok":    map[string]interface{}{"boolValue": true},
+			"tags": map[string]interface{}{"arrayValue": map[string]interface{}{"values": []interface{}{
+				map[string]interface{}{"stringValue": "a"},
+			}}},
+			"obj": map[string]interface{}{"kvlistValue": map[string]interface{}{"values": []interface{}{
+				map[string]interface{}{"key": "k", "value": map[string]interface{}{"stringValue": "v"}},
+			}}},
+		}, otlpAttributes(t, res["attributes"]))
+
+		scope := res["scope"].(map[string]interface{})
+		assert.Equal(t, "db", scope["name"])
+		assert.Equal(t, map[string]interface{}{
+			"tenant": map[string]interface{}{"stringValue": "acme"},
+		}, otlpAttributes(t, scope["attributes"]))
+
+		resource := otlpAttributes(t, res["resource"].(map[string]interface{})["attributes"])
+		assert.Equal(t, map[string]interface{}{"stringValue": "prod"}, resource["deployment.environment"])
+		assert.Equal(t, map[string]interface{}{"stringValue": "a b"}, resource["team"])
+		assert.True(t, strings.HasPrefix(resource["service.name"].(map[string]interface{})["stringValue"].(string), "unknown_service:"))
+	})
+
+	t.Run("OTEL_SERVICE_NAME", func(t *testing.T) {
+		defer logs.ExpSetOTELResource("service.name=ignored", "my-service")()
+
+		newLogger().Info("test msg")
+
+		res := decodeLine(t, buf)
+		resource := otlpAttributes(t, res["resource"].(map[string]interface{})["attributes"])
+		assert.Equal(t, map[string]interface{}{"stringValue": "my-service"}, resource["service.name"])
+	})
+
+	t.Run("exception and caller", func(t *testing.T) {
+		newLogger(logs.OptionCallerFunc()).E(errors.New("test error")).Error("test msg")
+
+		res := decodeLine(t, buf)
+		attributes := otlpAttributes(t, res["attributes"])
+		assert.Equal(t, map[string]interface{}{"stringValue": "test error"}, attributes["exception.message"])
+		assert.Equal(t, map[string]interface{}{"stringValue": "*errors.errorString"}, attributes["exception.type"])
+		assert.True(t, strings.HasSuffix(attributes["code.filepath"].(map[string]interface{})["stringValue"].(string), "otlp_test.go"))
+		assert.NotEmpty(t, attributes["code.lineno"])
+		assert.Equal(t, map[string]interface{}{"stringValue": "github.com/rtkym/logs-go_test.TestOTLPWriter.func4"}, attributes["code.function"])
+
+		newLogger().E(NewStackError()).Error("test msg")
+
+		attributes = otlpAttributes(t, decodeLine(t, buf)["attributes"])
+		assert.Contains(t, attributes["exception.stacktrace"].(map[string]interface{})["stringValue"], "otlp_test.go:")
+	})
+
+	t.Run("sinks", func(t *testing.T) {
+		jsonBuf := &bytes.Buffer{}
+		logger := logs.NewWithOption(logs.OptionSinks(
+			logs.Sink{Format: "otlp-json", Writer: buf},
+			logs.Sink{Writer: jsonBuf},
+		))
+		logger.Set("tenant", "acme")
+		logger.V("k", "v").Info("test msg")
+
+		res := decodeLine(t, buf)
+		assert.Contains(t, otlpAttributes(t, res["scope"].(map[string]interface{})["attributes"]), "tenant")
+		assert.Contains(t, otlpAttributes(t, res["attributes"]), "k")
+
+		assert.Contains(t, jsonBuf.String(), `{"level":"info","tenant":"acme","k":"v",`)
+		assert.NotContains(t, jsonBuf.String(), "_scope")
+	})
+
+	t.Run("OptionWriter,LOG_FORMAT", func(t *testing.T) {
+		outputs := formatOutputs(t, "otlp-json", func(logger *logs.Logger) {
+			logger.Set("tenant", "acme")
+			logger.V("k", "v").Info("test msg")
+		})
+
+		for name, output := range outputs {
+			res := map[string]interface{}{}
+			assert.NoError(t, json.Unmarshal([]byte(output), &res), name)
+			assert.Equal(t, "info", res["severityText"], name)
+			assert.Equal(t, map[string]interface{}{"stringValue": "test msg"}, res["body"], name)
+			assert.Contains(t, otlpAttributes(t, res["scope"].(map[string]interface{})["attributes"]), "tenant", name)
+			assert.Contains(t, otlpAttributes(t, res["attributes"]), "k", name)
+			assert.NotContains(t, output, "_scope", name)
+		}
+
+		assert.Len(t, outputs, 2)
+	})
+}
@@ -0,0 +1,293 @@
+package logs
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// errNotObject is returned when a line is not a JSON object.
+var errNotObject = errors.New("not a JSON object")
+
+// recordField is a field of a record.
+type recordField struct {
+	key   string
+	value interface{}
+}
+
+// record is a JSON object decoded in the order of fields, which is used by formats reshaping zerolog's JSON lines.
+// Values are record, []interface{}, string, json.Number, bool or nil.
+type record []recordField
+
+// decodeRecord decodes a JSON line, or a CBOR record in binary_log builds, into a record.
+func decodeRecord(p []byte) (record, error) {
+	if binaryLog {
+		line, err := cborToJSON(p)
+		if err != nil {
+			return nil, err
+		}
+
+		p = line
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(p))
+	dec.UseNumber()
+
+	v, err := decodeValue(dec)
+	if err != nil {
+		return nil, err
+	}
+
+	r, ok := v.(record)
+	if !ok {
+		return nil, errNotObject
+	}
+
+	return r, nil
+}
+
+// decodeValue decodes the next value from dec.
+func decodeValue(dec *json.Decoder) (interface{}, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err // nolint:wrapcheck
+	}
+
+	delim, ok := token.(json.Delim)
+	if !ok {
+		return token, nil
+	}
+
+	switch delim {
+	case '{':
+		r := record{}
+
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return nil, err // nolint:wrapcheck
+			}
+
+			value, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+
+			r = append(r, recordField{key: key.(string), value: value}) // nolint:forcetypeassert
+		}
+
+		_, err = dec.Token()
+
+		return r, err // nolint:wrapcheck
+	case '[':
+		a := []interface{}{}
+
+		for dec.More() {
+			value, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+
+			a = append(a, value)
+		}
+
+		_, err = dec.Token()
+
+		return a, err // nolint:wrapcheck
+	}
+
+	return nil, errNotObject
+}
+
+// get returns the value of key.
+func (x record) get(key string) (interface{}, bool) {
+	for _, f := range x {
+		if f.key == key {
+			return f.value, true
+		}
+	}
+
+	return nil, false
+}
+
+// getString returns the value of key if it is a string.
+func (x record) getString(key string) (string, bool) {
+	v, ok := x.get(key)
+	if !ok {
+		return "", false
+	}
+
+	s, ok := v.(string)
+
+	return s, ok
+}
+
+// set replaces the value of key, or appends it if key is not in the record.
+func (x record) set(key string, value interface{}) record {
+	for i := range x {
+		if x[i].key == key {
+			x[i].value = value
+
+			return x
+		}
+	}
+
+	return append(x, recordField{key: key, value: value})
+}
+
+// reshapeWriter renders zerolog's JSON lines in another shape, such as the structured logging of a service.
+// Each line is decoded into a record, reshaped by reshape, and encoded by encode, which is appendJSON by default.
+// Lines which are not JSON objects are written as is.
+type reshapeWriter struct {
+	out     io.Writer
+	reshape func(record) record
+	encode  func(buf *bytes.Buffer, r record)
+
+	// scope is set if the format needs the scope field. See scopeWriter.
+	scope bool
+	// errorCaller is set if the format needs the caller of errors. See errorReporter.
+	errorCaller bool
+}
+
+// newReshapeWriter returns a new reshapeWriter encoding the records reshaped by reshape in JSON.
+func newReshapeWriter(out io.Writer, reshape func(record) record) *reshapeWriter {
+	return &reshapeWriter{out: out, reshape: reshape, encode: func(buf *bytes.Buffer, r record) { appendJSON(buf, r) }}
+}
+
+// Write writes p reshaped. Lines which are not JSON objects are written as is.
+func (x *reshapeWriter) Write(p []byte) (int, error) {
+	r, err := decodeRecord(p)
+	if err != nil {
+		return x.out.Write(p) // nolint:wrapcheck
+	}
+
+	buf := &bytes.Buffer{}
+	x.encode(buf, x.reshape(r))
+	buf.WriteByte('\n')
+
+	if _, err := x.out.Write(buf.Bytes()); err != nil {
+		return 0, err // nolint:wrapcheck
+	}
+
+	return len(p), nil
+}
+
+// Sync flushes the output.
+func (x *reshapeWriter) Sync() error {
+	return syncWriter(x.out)
+}
+
+// Close closes the output.
+func (x *reshapeWriter) Close() error {
+	return closeWriter(x.out)
+}
+
+func (x *reshapeWriter) scoped() bool {
+	return x.scope
+}
+
+func (x *reshapeWriter) reportsErrors() bool {
+	return x.errorCaller
+}
+
+// MarshalJSON encodes the record in the order of fields.
+func (x record) MarshalJSON() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	appendJSON(buf, x)
+
+	return buf.Bytes(), nil
+}
+
+// appendJSON encodes v, which is a value of record, to buf without escaping HTML as zerolog.
+func appendJSON(buf *bytes.Buffer, v interface{}) {
+	switch value := v.(type) {
+	case record:
+		buf.WriteByte('{')
+
+		for i, f := range value {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+
+			appendJSON(buf, f.key)
+			buf.WriteByte(':')
+			appendJSON(buf, f.value)
+		}
+
+		buf.WriteByte('}')
+	case []interface{}:
+		buf.WriteByte('[')
+
+		for i, elem := range value {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+
+			appendJSON(buf, elem)
+		}
+
+		buf.WriteByte(']')
+	case json.Number:
+		buf.WriteString(value.String())
+	default:
+		enc := json.NewEncoder(buf)
+		enc.SetEscapeHTML(false)
+
+		if err := enc.Encode(value); err != nil {
+			buf.WriteString("null")
+
+			return
+		}
+
+		buf.Truncate(buf.Len() - 1) // newline written by Encode
+	}
+}
+
+// recordUnixTime converts the value of the time field in the UNIX time format of zerolog.TimeFieldFormat.
+func recordUnixTime(value interface{}) (time.Time, bool) {
+	n, ok := value.(json.Number)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	i, err := n.Int64()
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	switch zerolog.TimeFieldFormat {
+	case zerolog.TimeFormatUnixMs:
+		return time.UnixMilli(i), true
+	case zerolog.TimeFormatUnixMicro:
+		return time.UnixMicro(i), true
+	case zerolog.TimeFormatUnixNano:
+		return time.Unix(0, i), true
+	}
+
+	return time.Unix(i, 0), true
+}
+
+// recordStackTrace formats the frames of a stack trace like the stack trace of Go panics.
+func recordStackTrace(frames []interface{}) string {
+	buf := &strings.Builder{}
+
+	for _, v := range frames {
+		frame, ok := v.(record)
+		if !ok {
+			continue
+		}
+
+		fn, _ := frame.getString("func")
+		file, _ := frame.getString("file")
+		line, _ := frame.get("line")
+		fmt.Fprintf(buf, "%s(...)\n\t%s:%v\n", fn, file, line)
+	}
+
+	return buf.String()
+}
@@ -0,0 +1,100 @@
+package logs
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// PanicFieldName is the field name used for the value of a recovered panic.
+var PanicFieldName = "panic" // nolint:gochecknoglobals
+
+// Recover recovers a panic of the calling goroutine and outputs it at error level with the panic value
+// and the stack trace of the panic. It must be called directly by defer.
+//
+//	defer logger.Recover()
+func (x *Logger) Recover() {
+	if r := recover(); r != nil {
+		x.logPanic(r)
+	}
+}
+
+// RecoverAndPanic is like Recover, but panics again with the recovered value after it is output.
+//
+//	defer logger.RecoverAndPanic()
+func (x *Logger) RecoverAndPanic() {
+	if r := recover(); r != nil {
+		x.logPanic(r)
+		panic(r)
+	}
+}
+
+// Go runs fn in a new goroutine. A panic in fn is recovered and output as Recover does.
+func (x *Logger) Go(fn func()) {
+	go func() {
+		defer x.Recover()
+
+		fn()
+	}()
+}
+
+// Recover recovers a panic of the calling goroutine and outputs it with the global logger.
+// It must be called directly by defer.
+func Recover() {
+	if r := recover(); r != nil {
+		gLogger.logPanic(r)
+	}
+}
+
+// RecoverAndPanic is like Recover, but panics again with the recovered value after it is output.
+func RecoverAndPanic() {
+	if r := recover(); r != nil {
+		gLogger.logPanic(r)
+		panic(r)
+	}
+}
+
+// Go runs fn in a new goroutine. A panic in fn is recovered and output with the global logger.
+func Go(fn func()) {
+	gLogger.Go(fn)
+}
+
+// logPanic outputs the recovered value r. It must be called by the function deferred for recover.
+func (x *Logger) logPanic(r interface{}) {
+	var pcs [maxStackDepth]uintptr
+
+	// skip runtime.Callers, logPanic and the deferred function.
+	n := runtime.Callers(3, pcs[:])
+
+	entry := x.Entry()
+	entry.pcs = panicCallers(pcs[:n])
+	entry.V(PanicFieldName, fmt.Sprint(r))
+
+	if err, ok := r.(error); ok {
+		entry.E(err)
+	}
+
+	entry.Error("recovered from panic")
+}
+
+// panicCallers returns the program counters from the function which panicked,
+// skipping the frames of the runtime which raised the panic.
+func panicCallers(pcs []uintptr) []uintptr {
+	for i, pc := range pcs {
+		if fn := runtime.FuncForPC(pc - 1); fn != nil && fn.Name() == "runtime.gopanic" {
+			pcs = pcs[i+1:]
+
+			for len(pcs) > 1 {
+				if fn := runtime.FuncForPC(pcs[0] - 1); fn == nil || !strings.HasPrefix(fn.Name(), "runtime.") {
+					break
+				}
+
+				pcs = pcs[1:]
+			}
+
+			break
+		}
+	}
+
+	return append([]uintptr(nil), pcs...)
+}
@@ -0,0 +1,268 @@
+package logs
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// ErrInvalidSink is returned when a sink spec cannot be parsed.
+var ErrInvalidSink = errors.New("invalid log sink")
+
+// Sink is a destination of messages within a range of levels.
+type Sink struct {
+	// Levels is the range of levels, such as "info" (info and above), "debug..warn" or "..info".
+	// An empty range accepts all levels.
+	Levels string
+	// Format is a format registered by RegisterFormat. The default is "json".
+	Format string
+	// Output is "stdout", "stderr" or a file URL as OptionOutput. The default is "stdout".
+	Output string
+	// Writer is the destination used instead of Output if it is set.
+	Writer io.Writer
+}
+
+// OptionSinks returns an OptionFunc for configuring the sinks, which replace the writer of the logger.
+// Each message is written to every sink whose range includes its level.
+// The level of the logger is applied before the sinks. Unless it is set by OptionLevel,
+// such as by LOG_LEVEL, it is the lowest level of the sinks.
+func OptionSinks(sinks ...Sink) OptionFunc {
+	return func(opt *Option) {
+		opt.sinks = sinks
+	}
+}
+
+// optionSinksEnv returns an OptionFunc for configuring the sinks by LOG_SINKS.
+func optionSinksEnv(value string) OptionFunc {
+	return func(opt *Option) {
+		if value == "" {
+			return
+		}
+
+		sinks, err := ParseSinks(value)
+		if err != nil {
+			opt.addError(err)
+
+			return
+		}
+
+		OptionSinks(sinks...)(opt)
+	}
+}
+
+// ParseSinks parses semicolon-separated sinks of "<levels>:<format>:<output>",
+// such as "info:json:stdout;error:console:stderr;trace:json:file:///var/log/debug.log".
+// The format and the output may be omitted.
+func ParseSinks(spec string) ([]Sink, error) {
+	var sinks []Sink
+
+	for _, s := range strings.Split(spec, ";") {
+		if strings.TrimSpace(s) == "" {
+			continue
+		}
+
+		parts := strings.SplitN(strings.TrimSpace(s), ":", 3) // nolint:gomnd
+		sink := Sink{Levels: parts[0]}
+
+		if len(parts) > 1 {
+			sink.Format = parts[1]
+		}
+
+		if len(parts) > 2 { // nolint:gomnd
+			sink.Output = parts[2]
+		}
+
+		if _, _, err := parseLevelRange(sink.Levels); err != nil {
+			return nil, fmt.Errorf("%w: %q: %v", ErrInvalidSink, s, err) // nolint:errorlint
+		}
+
+		sinks = append(sinks, sink)
+	}
+
+	if len(sinks) == 0 {
+		return nil, fmt.Errorf("%w: %q has no sink", ErrInvalidSink, spec)
+	}
+
+	return sinks, nil
+}
+
+// parseLevelRange parses the range of levels of Sink.
+func parseLevelRange(levels string) (Level, Level, error) {
+	min, max := TraceLevel, PanicLevel
+
+	minSpec, maxSpec := levels, ""
+	if i := strings.Index(levels, ".."); i >= 0 {
+		minSpec, maxSpec = levels[:i], levels[i+2:]
+	}
+
+	var err error
+
+	if strings.TrimSpace(minSpec) != "" {
+		if min, err = ParseLevel(minSpec); err != nil {
+			return min, max, err
+		}
+	}
+
+	if strings.TrimSpace(maxSpec) != "" {
+		if max, err = ParseLevel(maxSpec); err != nil {
+			return min, max, err
+		}
+	}
+
+	return min, max, nil
+}
+
+// sinkWriter is a sink opened by newLevelRouter.
+type sinkWriter struct {
+	min    Level
+	max    Level
+	writer io.Writer
+}
+
+// levelRouter is a zerolog.LevelWriter which writes messages to the sinks whose range includes their levels.
+type levelRouter struct {
+	sinks []sinkWriter
+}
+
+// newLevelRouter opens the sinks.
+func newLevelRouter(sinks []Sink) (*levelRouter, error) {
+	router := &levelRouter{}
+
+	for _, sink := range sinks {
+		w, err := router.open(sink)
+		if err != nil {
+			router.Close()
+
+			return nil, err
+		}
+
+		router.sinks = append(router.sinks, w)
+	}
+
+	return router, nil
+}
+
+// open opens the sink.
+func (x *levelRouter) open(sink Sink) (sinkWriter, error) {
+	min, max, err := parseLevelRange(sink.Levels)
+	if err != nil {
+		return sinkWriter{}, fmt.Errorf("%w: %q: %v", ErrInvalidSink, sink.Levels, err) // nolint:errorlint
+	}
+
+	name := sink.Format
+	if name == "" {
+		name = "json"
+	}
+
+	format, err := lookupFormat(name)
+	if err != nil {
+		return sinkWriter{}, err
+	}
+
+	w := sink.Writer
+	if w == nil {
+		if w, err = openOutput(sink.Output); err != nil {
+			return sinkWriter{}, err
+		}
+	}
+
+	return sinkWriter{min: min, max: max, writer: format(w)}, nil
+}
+
+// Write writes p to all sinks.
+func (x *levelRouter) Write(p []byte) (int, error) {
+	return x.WriteLevel(zerolog.NoLevel, p)
+}
+
+// WriteLevel writes p to the sinks whose range includes level.
+// Messages without level are written to all sinks.
+// The scope field is removed for the sinks which do not need it.
+func (x *levelRouter) WriteLevel(level Level, p []byte) (int, error) {
+	var firstErr error
+
+	for _, sink := range x.sinks {
+		if level != zerolog.NoLevel && (level < sink.min || sink.max < level) {
+			continue
+		}
+
+		line := p
+		if !wantsScope(sink.writer) {
+			line = stripScope(p)
+		}
+
+		var err error
+		if writer, ok := sink.writer.(zerolog.LevelWriter); ok {
+			_, err = writer.WriteLevel(level, line)
+		} else {
+			_, err = sink.writer.Write(line)
+		}
+
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return len(p), firstErr
+}
+
+// minLevel returns the lowest level of the sinks.
+func (x *levelRouter) minLevel() Level {
+	min := PanicLevel
+
+	for _, sink := range x.sinks {
+		if sink.min < min {
+			min = sink.min
+		}
+	}
+
+	return min
+}
+
+func (x *levelRouter) scoped() bool {
+	for _, sink := range x.sinks {
+		if wantsScope(sink.writer) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (x *levelRouter) reportsErrors() bool {
+	for _, sink := range x.sinks {
+		if wantsErrorCaller(sink.writer) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Sync flushes all sinks.
+func (x *levelRouter) Sync() error {
+	var firstErr error
+
+	for _, sink := range x.sinks {
+		if err := syncWriter(sink.writer); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
+
+// Close closes all sinks.
+func (x *levelRouter) Close() error {
+	var firstErr error
+
+	for _, sink := range x.sinks {
+		if err := closeWriter(sink.writer); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	return firstErr
+}
@@ -0,0 +1,125 @@
+package logs_test
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	logs "github.com/rtkym/logs-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOptionSinks(t *testing.T) {
+	t.Run("levels", func(t *testing.T) {
+		info, errs, all := &bytes.Buffer{}, &CloseBuffer{}, &bytes.Buffer{}
+		logger, err := logs.NewWithOptionE(
+			logs.OptionLevel("trace"),
+			logs.OptionSinks(
+				logs.Sink{Levels: "info..warn", Writer: info},
+				logs.Sink{Levels: "error", Format: "console", Writer: errs},
+				logs.Sink{Writer: all},
+			),
+		)
+		assert.NoError(t, err)
+
+		logger.Trace("trace msg")
+		logger.Info("info msg")
+		logger.Warn("warn msg")
+		logger.Error("error msg")
+
+		assert.NotContains(t, info.String(), "trace msg")
+		assert.Contains(t, info.String(), `"level":"info"`)
+		assert.Contains(t, info.String(), `"message":"warn msg"`)
+		assert.NotContains(t, info.String(), "error msg")
+
+		assert.NotContains(t, errs.String(), "warn msg")
+		assert.NotContains(t, errs.String(), `"level"`)
+		assert.Contains(t, errs.String(), "error msg")
+
+		for _, msg := range []string{"trace msg", "info msg", "warn msg", "error msg"} {
+			assert.Contains(t, all.String(), msg)
+		}
+
+		assert.NoError(t, logger.Close())
+		assert.Equal(t, "close", errs.calls[len(errs.calls)-1])
+	})
+
+	t.Run("async", func(t *testing.T) {
+		info, errs := &bytes.Buffer{}, &bytes.Buffer{}
+		logger := logs.NewWithOption(
+			logs.OptionAsync(logs.AsyncConfig{}),
+			logs.OptionSinks(logs.Sink{Levels: "..warn", Writer: info}, logs.Sink{Levels: "error", Writer: errs}),
+		)
+
+		logger.Info("info msg")
+		logger.Error("error msg")
+		assert.NoError(t, logger.Close())
+
+		assert.Contains(t, info.String(), "info msg")
+		assert.NotContains(t, info.String(), "error msg")
+		assert.Contains(t, errs.String(), "error msg")
+		assert.NotContains(t, errs.String(), "info msg")
+	})
+
+	t.Run("LOG_SINKS", func(t *testing.T) {
+		dir := t.TempDir()
+		defer logs.ExpSetLogLevel("")()
+		defer logs.ExpSetLogSinks("info:json:stderr;trace::file://" + filepath.Join(dir, "debug.log"))()
+
+		logger := logs.New()
+		logger.Trace("trace msg")
+		assert.NoError(t, logger.Close())
+
+		assert.Contains(t, readFile(t, filepath.Join(dir, "debug.log")), `"message":"trace msg"`)
+	})
+
+	t.Run("level", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		logger := logs.NewWithOption(logs.OptionSinks(logs.Sink{Levels: "error", Writer: buf}, logs.Sink{Levels: "debug..info", Writer: buf}))
+		logger.Trace("trace msg")
+		logger.Debug("debug msg")
+
+		assert.NotContains(t, buf.String(), "trace msg")
+		assert.Contains(t, buf.String(), "debug msg")
+
+		buf.Reset()
+		logger = logs.NewWithOption(logs.OptionLevel("warn"), logs.OptionSinks(logs.Sink{Levels: "trace", Writer: buf}))
+		logger.Info("info msg")
+		logger.Warn("warn msg")
+
+		assert.NotContains(t, buf.String(), "info msg")
+		assert.Contains(t, buf.String(), "warn msg")
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, opt := range []logs.OptionFunc{
+			logs.OptionSinks(logs.Sink{Levels: "verbose"}),
+			logs.OptionSinks(logs.Sink{Format: "unknown"}),
+			logs.OptionSinks(logs.Sink{Output: "http://example.com"}),
+			logs.ExpOptionSinksEnv(";"),
+			logs.ExpOptionSinksEnv("info..verbose:json"),
+		} {
+			buf := &bytes.Buffer{}
+			_, err := logs.NewWithOptionE(func(opt *logs.Option) { opt.Writer = jsonWriter(buf) }, opt)
+			assert.Error(t, err)
+		}
+	})
+}
+
+func TestParseSinks(t *testing.T) {
+	sinks, err := logs.ParseSinks("info:json:stdout; error:console:stderr ;trace::file:///var/log/debug.log?compress=true;..debug")
+	assert.NoError(t, err)
+	assert.Equal(t, []logs.Sink{
+		{Levels: "info", Format: "json", Output: "stdout"},
+		{Levels: "error", Format: "console", Output: "stderr"},
+		{Levels: "trace", Format: "", Output: "file:///var/log/debug.log?compress=true"},
+		{Levels: "..debug"},
+	}, sinks)
+
+	_, err = logs.ParseSinks("")
+	assert.ErrorIs(t, err, logs.ErrInvalidSink)
+
+	_, err = logs.ParseSinks("verbose:json")
+	assert.ErrorIs(t, err, logs.ErrInvalidSink)
+	assert.Contains(t, err.Error(), `invalid log level: "verbose"`)
+}
@@ -0,0 +1,120 @@
+package logs
+
+import (
+	"errors"
+	"runtime"
+
+	pkgerrors "github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// maxStackDepth is the maximum number of frames captured for a stack trace.
+const maxStackDepth = 64
+
+// Frame is one frame of a stack trace.
+type Frame struct {
+	Func string `json:"func"`
+	File string `json:"file"`
+	Line int    `json:"line"`
+}
+
+// MarshalZerologObject implements zerolog.LogObjectMarshaler.
+func (x Frame) MarshalZerologObject(e *zerolog.Event) {
+	e.Str("func", x.Func).Str("file", x.File).Int("line", x.Line)
+}
+
+// frameArray is the output of a stack trace.
+type frameArray []Frame
+
+// MarshalZerologArray implements zerolog.LogArrayMarshaler.
+func (x frameArray) MarshalZerologArray(a *zerolog.Array) {
+	for _, frame := range x {
+		a.Object(frame)
+	}
+}
+
+// StackTracer is implemented by errors which carry the program counters where they were created,
+// as returned by runtime.Callers.
+type StackTracer interface {
+	Callers() []uintptr
+}
+
+// OptionStacktraceLevel returns an OptionFunc for attaching a stack trace to messages at level and above.
+func OptionStacktraceLevel(level Level) OptionFunc {
+	return func(opt *Option) {
+		opt.StacktraceLevel = level
+	}
+}
+
+// frames converts program counters returned by runtime.Callers into frames.
+func frames(pcs []uintptr) []Frame {
+	result := make([]Frame, 0, len(pcs))
+	iter := runtime.CallersFrames(pcs)
+
+	for {
+		frame, more := iter.Next()
+		if frame.Function != "" || frame.File != "" {
+			result = append(result, Frame{Func: frame.Function, File: frame.File, Line: frame.Line})
+		}
+
+		if !more {
+			return result
+		}
+	}
+}
+
+// errorStack returns the stack trace of the innermost error in the chain of err which carries one.
+// It supports StackTracer and the stack trace of github.com/pkg/errors.
+func errorStack(err error) []Frame {
+	var stack []Frame
+
+	for ; err != nil; err = errors.Unwrap(err) {
+		if frames := errorFrames(err); frames != nil {
+			stack = frames
+		}
+	}
+
+	return stack
+}
+
+// errorFrames returns the stack trace carried by err itself.
+func errorFrames(err error) []Frame {
+	switch e := err.(type) { // nolint:errorlint
+	case StackTracer:
+		return frames(e.Callers())
+	case interface{ StackTrace() pkgerrors.StackTrace }:
+		return frames(pkgCallers(e.StackTrace()))
+	}
+
+	return nil
+}
+
+// pkgCallers converts a stack trace of github.com/pkg/errors into program counters.
+// A pkgerrors.Frame is a program counter + 1, the same as those returned by runtime.Callers.
+func pkgCallers(stack pkgerrors.StackTrace) []uintptr {
+	pcs := make([]uintptr, len(stack))
+	for i, frame := range stack {
+		pcs[i] = uintptr(frame)
+	}
+
+	return pcs
+}
+
+// addStack adds the stack trace of the caller to ev if level is at or above the stacktrace level of the logger.
+// The stack trace set to the entry, such as of a recovered panic, is always added.
+func (x *LogEntry) addStack(ev *zerolog.Event, level zerolog.Level, skip int) {
+	if x.pcs != nil {
+		ev.Array(zerolog.ErrorStackFieldName, frameArray(frames(x.pcs)))
+
+		return
+	}
+
+	if level < x.logger.stacktraceLevel {
+		return
+	}
+
+	var pcs [maxStackDepth]uintptr
+
+	n := x.callers(skip, pcs[:])
+	ev.Array(zerolog.ErrorStackFieldName, frameArray(frames(pcs[:n])))
+}
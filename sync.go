@@ -0,0 +1,123 @@
+package logs
+
+import (
+	"context"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+// Syncer is implemented by writers which buffer data, such as files and asynchronous writers.
+type Syncer interface {
+	Sync() error
+}
+
+// loggerCore holds the resources shared by a Logger and its children.
+type loggerCore struct {
+	writer   io.Writer
+	exitFunc func(code int)
+
+	// scoped is set if the writer needs the scope field. See scopeWriter.
+	scoped bool
+	// reportErrors is set if the writer needs the caller of errors. See errorReporter.
+	reportErrors bool
+
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// sync flushes the writer.
+func (x *loggerCore) sync() error {
+	return syncWriter(x.writer)
+}
+
+// close flushes and closes the writer once.
+func (x *loggerCore) close() error {
+	x.closeOnce.Do(func() {
+		if err := x.sync(); err != nil {
+			x.closeErr = err
+		}
+
+		if err := closeWriter(x.writer); err != nil && x.closeErr == nil {
+			x.closeErr = err
+		}
+	})
+
+	return x.closeErr
+}
+
+// Sync flushes the data buffered by the writer of the logger.
+// It is propagated to writers implementing Syncer or Flush() error, including the output of the console writer.
+func (x *Logger) Sync() error {
+	return x.core.sync()
+}
+
+// Close flushes and closes the writer of the logger, which is shared with its children.
+// It is propagated to writers implementing io.Closer, except the standard output and error.
+// Messages output after Close may be lost.
+func (x *Logger) Close() error {
+	return x.core.close()
+}
+
+// Sync flushes the data buffered by the writer of the global logger.
+func Sync() error {
+	return gLogger.Sync()
+}
+
+// Shutdown flushes and closes the writer of the global logger.
+// It returns ctx.Err() if ctx is done before the writer is closed.
+func Shutdown(ctx context.Context) error {
+	done := make(chan error, 1)
+	logger := gLogger
+
+	go func() { done <- logger.Close() }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err() // nolint:wrapcheck
+	}
+}
+
+// isStdio reports whether w is the standard output or error, which are not owned by loggers.
+func isStdio(w io.Writer) bool {
+	return w == os.Stdout || w == os.Stderr
+}
+
+// syncWriter flushes w if it implements Syncer or Flush() error,
+// and the writer wrapped by zerolog.ConsoleWriter.
+func syncWriter(w io.Writer) error {
+	if isStdio(w) {
+		return nil
+	}
+
+	switch writer := w.(type) {
+	case Syncer:
+		return writer.Sync() // nolint:wrapcheck
+	case interface{ Flush() error }:
+		return writer.Flush() // nolint:wrapcheck
+	case *zerolog.ConsoleWriter:
+		return syncWriter(writer.Out)
+	}
+
+	return nil
+}
+
+// closeWriter closes w if it implements io.Closer, and the writer wrapped by zerolog.ConsoleWriter.
+func closeWriter(w io.Writer) error {
+	if isStdio(w) {
+		return nil
+	}
+
+	switch writer := w.(type) {
+	case io.Closer:
+		return writer.Close() // nolint:wrapcheck
+	case *zerolog.ConsoleWriter:
+		return closeWriter(writer.Out)
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package logs
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Field names of the trace context added by LogEntry.TraceContext.
+// They are mapped to the fields of the format, such as "logging.googleapis.com/trace" of gcp.
+// nolint:gochecknoglobals
+var (
+	TraceIDFieldName      = "trace_id"
+	SpanIDFieldName       = "span_id"
+	TraceSampledFieldName = "trace_sampled"
+)
+
+// HTTPRequestFieldName is the field name of the request added by LogEntry.HTTPRequest.
+var HTTPRequestFieldName = "httpRequest" // nolint:gochecknoglobals
+
+// HTTPRequest is an HTTP request and its response, which is output in the form of the HttpRequest of Cloud Logging.
+type HTTPRequest struct {
+	Method       string
+	URL          string
+	Status       int
+	RequestSize  int64
+	ResponseSize int64
+	UserAgent    string
+	RemoteIP     string
+	ServerIP     string
+	Referer      string
+	Latency      time.Duration
+	Protocol     string
+}
+
+// NewHTTPRequest returns an HTTPRequest of r and its response.
+func NewHTTPRequest(r *http.Request, status int, responseSize int64, latency time.Duration) *HTTPRequest {
+	return &HTTPRequest{
+		Method:       r.Method,
+		URL:          r.URL.String(),
+		Status:       status,
+		RequestSize:  r.ContentLength,
+		ResponseSize: responseSize,
+		UserAgent:    r.UserAgent(),
+		RemoteIP:     r.RemoteAddr,
+		Referer:      r.Referer(),
+		Latency:      latency,
+		Protocol:     r.Proto,
+	}
+}
+
+// MarshalZerologObject implements zerolog.LogObjectMarshaler. Empty fields are omitted.
+func (x *HTTPRequest) MarshalZerologObject(e *zerolog.Event) {
+	appendStr := func(key, value string) {
+		if value != "" {
+			e.Str(key, value)
+		}
+	}
+
+	appendStr("requestMethod", x.Method)
+	appendStr("requestUrl", x.URL)
+
+	if x.Status != 0 {
+		e.Int("status", x.Status)
+	}
+
+	if x.RequestSize > 0 {
+		e.Str("requestSize", strconv.FormatInt(x.RequestSize, 10))
+	}
+
+	if x.ResponseSize > 0 {
+		e.Str("responseSize", strconv.FormatInt(x.ResponseSize, 10))
+	}
+
+	appendStr("userAgent", x.UserAgent)
+	appendStr("remoteIp", x.RemoteIP)
+	appendStr("serverIp", x.ServerIP)
+	appendStr("referer", x.Referer)
+
+	if x.Latency > 0 {
+		e.Str("latency", strconv.FormatFloat(x.Latency.Seconds(), 'f', -1, 64)+"s")
+	}
+
+	appendStr("protocol", x.Protocol)
+}
+
+// TraceContext adds the trace ID, the span ID and the sampling decision to log message.
+func (x *LogEntry) TraceContext(traceID, spanID string, sampled bool) *LogEntry {
+	x.Str(TraceIDFieldName, traceID)
+
+	if spanID != "" {
+		x.Str(SpanIDFieldName, spanID)
+	}
+
+	return x.Bool(TraceSampledFieldName, sampled)
+}
+
+// HTTPRequest adds the request to log message.
+func (x *LogEntry) HTTPRequest(req *HTTPRequest) *LogEntry {
+	if req == nil {
+		return x.V(HTTPRequestFieldName, nil)
+	}
+
+	return x.V(HTTPRequestFieldName, req)
+}